@@ -0,0 +1,276 @@
+package pqueue
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+// adaptiveWindow caps the number of timings a running mean weighs, so a
+// strategy's mean keeps following the workload as it drifts
+const adaptiveWindow = 64
+
+// presortedBuckets is the number of bands descent fractions are grouped in
+const presortedBuckets = 5
+
+// AdaptiveSelector learns which strategy sorts each shape of data fastest.
+// Queues given one with WithAdaptiveSelector time every AutoStrategy sort,
+// keyed by data type, size bucket and presortedness bucket. Most sorts use
+// the fastest strategy measured for their shape, or the rule-based choice
+// until one is; with probability epsilon a sort tries another eligible
+// strategy instead. One selector may be shared by queues of any element
+// type, each only given strategies that apply to it, and is safe for
+// concurrent use.
+type AdaptiveSelector struct {
+	mu      sync.Mutex
+	epsilon float64
+	table   map[sortShape]map[SortStrategy]*strategyTiming
+}
+
+// sortShape groups inputs expected to favor the same strategy
+type sortShape struct {
+	dataType   DataType
+	sizeBucket int // bit length of the size, so each bucket spans a doubling
+	presorted  int // band of the sampled descent fraction
+}
+
+// strategyTiming is the running mean cost of a strategy on one shape
+type strategyTiming struct {
+	runs  int
+	nanos float64 // mean nanoseconds per element
+}
+
+// NewAdaptiveSelector creates an AdaptiveSelector exploring with
+// probability epsilon. Zero never explores, which suits a selector warm
+// started from a learned table.
+func NewAdaptiveSelector(epsilon float64) *AdaptiveSelector {
+	return &AdaptiveSelector{
+		epsilon: epsilon,
+		table:   map[sortShape]map[SortStrategy]*strategyTiming{},
+	}
+}
+
+// best returns the strategy of allowed with the lowest mean time for
+// shape. Queues of one shape may differ in which strategies apply to them,
+// such as key-based sorts for a custom order, so others are skipped.
+func (a *AdaptiveSelector) best(shape sortShape, allowed []SortStrategy) (SortStrategy, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	best := AutoStrategy
+	var fastest *strategyTiming
+	for s, t := range a.table[shape] {
+		if !slices.Contains(allowed, s) {
+			continue
+		}
+		if fastest == nil || t.nanos < fastest.nanos || (t.nanos == fastest.nanos && s < best) {
+			best, fastest = s, t
+		}
+	}
+	return best, fastest != nil
+}
+
+// choose picks the strategy for a sort of shape: a random one of
+// candidates with probability epsilon, otherwise the fastest of them
+// measured, or fallback if none has been
+func (a *AdaptiveSelector) choose(shape sortShape, fallback SortStrategy, candidates []SortStrategy) SortStrategy {
+	if a.epsilon > 0 && rand.Float64() < a.epsilon {
+		return candidates[rand.IntN(len(candidates))]
+	}
+	if s, ok := a.best(shape, candidates); ok {
+		return s
+	}
+	return fallback
+}
+
+// record adds the time strategy took to sort n elements of shape
+func (a *AdaptiveSelector) record(shape sortShape, strategy SortStrategy, elapsed time.Duration, n int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	timings := a.table[shape]
+	if timings == nil {
+		timings = map[SortStrategy]*strategyTiming{}
+		a.table[shape] = timings
+	}
+	t := timings[strategy]
+	if t == nil {
+		t = &strategyTiming{}
+		timings[strategy] = t
+	}
+	t.runs++
+	t.nanos += (float64(elapsed.Nanoseconds())/float64(n) - t.nanos) / float64(min(t.runs, adaptiveWindow))
+}
+
+// shape returns the shape the selector files the queue data under
+func (pq *PQueue[T]) shape() sortShape {
+	descents := pq.sampleDescents(pq.opts.selection().SampleSize)
+	return sortShape{
+		dataType:   pq.dataType,
+		sizeBucket: bits.Len(uint(pq.size)),
+		presorted:  min(int(descents*presortedBuckets), presortedBuckets-1),
+	}
+}
+
+// candidates returns the strategies worth exploring for the queue data:
+// the comparison sorts that stay O(n log n), plus the rule-based choice and
+// whichever key-based or parallel sorts apply. Counting sort is only tried
+// on integers whose sampled range is within SmallRange.
+func (pq *PQueue[T]) candidates(rule SortStrategy) []SortStrategy {
+	th := pq.opts.selection()
+	c := []SortStrategy{PdqStrategy, TimsortStrategy, IntrosortStrategy, MergeStrategy}
+	if pq.size <= 4*th.SmallSize {
+		c = append(c, InsertionStrategy)
+	}
+	if pq.refs != nil { // tracked sorts replace key-based strategies with pdqsort
+		rule = trackedStrategy(rule)
+	} else if _, ok := pq.integers(); ok {
+		c = append(c, RadixStrategy)
+		if r, _ := pq.sampleKeyRange(th.SampleSize); r <= th.SmallRange {
+			c = append(c, CountingStrategy)
+		}
+	} else if _, ok := pq.floats(); ok {
+		c = append(c, RadixStrategy)
+	} else if _, ok := pq.sequences(); ok {
+		c = append(c, MSDRadixStrategy)
+	}
+	if pq.opts.workerCount() > 1 && pq.size >= 2*parallelMinChunk {
+		c = append(c, ParallelMergeStrategy)
+	}
+	for _, s := range c {
+		if s == rule {
+			return c
+		}
+	}
+	return append(c, rule)
+}
+
+// adapt lets the selector override the rule-based strategy. It returns
+// the strategy to sort with and a function recording how long the sort
+// took, to be called once it finishes.
+func (pq *PQueue[T]) adapt(a *AdaptiveSelector, rule SortStrategy) (SortStrategy, func()) {
+	if pq.refs != nil {
+		rule = trackedStrategy(rule)
+	}
+	shape := pq.shape()
+	strategy := a.choose(shape, rule, pq.candidates(rule))
+	n, start := pq.size, time.Now()
+	return strategy, func() {
+		a.record(shape, strategy, time.Since(start), n)
+	}
+}
+
+// adaptiveEntry is the JSON form of one strategy's timing on one shape
+type adaptiveEntry struct {
+	DataType        string  `json:"dataType"`
+	SizeBucket      int     `json:"sizeBucket"`
+	PresortedBucket int     `json:"presortedBucket"`
+	Strategy        string  `json:"strategy"`
+	Runs            int     `json:"runs"`
+	NanosPerElement float64 `json:"nanosPerElement"`
+}
+
+// MarshalJSON exports the learned timings, one entry per shape and
+// strategy, so another selector can start from them with UnmarshalJSON
+func (a *AdaptiveSelector) MarshalJSON() ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entries := []adaptiveEntry{}
+	for shape, timings := range a.table {
+		for s, t := range timings {
+			entries = append(entries, adaptiveEntry{
+				DataType:        dataTypeName(shape.dataType),
+				SizeBucket:      shape.sizeBucket,
+				PresortedBucket: shape.presorted,
+				Strategy:        strategyName(s),
+				Runs:            t.runs,
+				NanosPerElement: t.nanos,
+			})
+		}
+	}
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON replaces the learned timings with ones exported by
+// MarshalJSON. Epsilon is kept. Entries naming unknown data types or
+// strategies fail with ErrInvalidTable.
+func (a *AdaptiveSelector) UnmarshalJSON(b []byte) error {
+	var entries []adaptiveEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+
+	table := map[sortShape]map[SortStrategy]*strategyTiming{}
+	for _, e := range entries {
+		dataType, ok := parseDataType(e.DataType)
+		if !ok {
+			return fmt.Errorf("%w: unknown data type %q", ErrInvalidTable, e.DataType)
+		}
+		strategy, ok := parseStrategy(e.Strategy)
+		if !ok || strategy == AutoStrategy {
+			return fmt.Errorf("%w: unknown sort strategy %q", ErrInvalidTable, e.Strategy)
+		}
+		if e.Runs <= 0 || e.NanosPerElement < 0 {
+			return fmt.Errorf("%w: invalid timing for %s", ErrInvalidTable, e.Strategy)
+		}
+
+		shape := sortShape{dataType: dataType, sizeBucket: e.SizeBucket, presorted: e.PresortedBucket}
+		if table[shape] == nil {
+			table[shape] = map[SortStrategy]*strategyTiming{}
+		}
+		table[shape][strategy] = &strategyTiming{runs: e.Runs, nanos: e.NanosPerElement}
+	}
+
+	a.mu.Lock()
+	a.table = table
+	a.mu.Unlock()
+	return nil
+}
+
+// strategyNames names the strategies in exported tables
+var strategyNames = [...]string{
+	AutoStrategy:          "Auto",
+	RadixStrategy:         "Radix",
+	CountingStrategy:      "Counting",
+	InsertionStrategy:     "Insertion",
+	TimsortStrategy:       "Timsort",
+	IntrosortStrategy:     "Introsort",
+	MergeStrategy:         "Merge",
+	QuickStrategy:         "Quick",
+	MSDRadixStrategy:      "MSDRadix",
+	PdqStrategy:           "Pdq",
+	ParallelMergeStrategy: "ParallelMerge",
+}
+
+// strategyName returns the name of s in exported tables
+func strategyName(s SortStrategy) string {
+	if s >= 0 && int(s) < len(strategyNames) {
+		return strategyNames[s]
+	}
+	return fmt.Sprintf("Strategy(%d)", int(s))
+}
+
+// parseStrategy returns the strategy named name
+func parseStrategy(name string) (SortStrategy, bool) {
+	for s, n := range strategyNames {
+		if n == name {
+			return SortStrategy(s), true
+		}
+	}
+	return 0, false
+}
+
+// parseDataType returns the data type dataTypeName gives name to
+func parseDataType(name string) (DataType, bool) {
+	for t := IntegerType; t <= GenericType; t++ {
+		if dataTypeName(t) == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,251 @@
+package pqueue
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestAdaptiveExplores tests that exploring sorts try several strategies,
+// sort correctly and file their timings under the right shape
+func TestAdaptiveExplores(t *testing.T) {
+	a := NewAdaptiveSelector(1)
+	for i := 0; i < 100; i++ {
+		data := generateRandomInts(2000)
+		pq := NewInts(data, WithAdaptiveSelector(a))
+		pq.Sort()
+		if !sort.IntsAreSorted(pq.ToSlice()) {
+			t.Fatalf("Sort %d: not sorted", i)
+		}
+	}
+
+	shape := sortShape{dataType: IntegerType, sizeBucket: 11, presorted: 2}
+	if got := len(a.table); got != 1 {
+		t.Errorf("%d shapes recorded, want 1: %v", got, a.table)
+	}
+	if got := len(a.table[shape]); got < 4 {
+		t.Errorf("Only %d strategies explored", got)
+	}
+	if _, ok := a.table[shape][InsertionStrategy]; ok {
+		t.Error("Insertion sort explored on 2000 elements")
+	}
+
+	// Inputs small enough for insertion sort are left to the rules
+	NewInts([]int{3, 1, 2}, WithAdaptiveSelector(a)).Sort()
+	if got := len(a.table); got != 1 {
+		t.Errorf("Small sort recorded: %d shapes", got)
+	}
+}
+
+// TestAdaptiveConverges tests that without exploration the fastest
+// strategy measured is chosen, and that means follow changing timings
+func TestAdaptiveConverges(t *testing.T) {
+	a := NewAdaptiveSelector(0)
+	shape := sortShape{dataType: StructType, sizeBucket: 12, presorted: 2}
+	candidates := []SortStrategy{PdqStrategy, TimsortStrategy}
+
+	if s := a.choose(shape, MergeStrategy, candidates); s != MergeStrategy {
+		t.Errorf("Untrained selector chose %v, want the rule's choice", s)
+	}
+
+	a.record(shape, PdqStrategy, 10*time.Millisecond, 4000)
+	a.record(shape, TimsortStrategy, 20*time.Millisecond, 4000)
+	if s := a.choose(shape, MergeStrategy, candidates); s != PdqStrategy {
+		t.Errorf("Chose %v, want the faster Pdq", s)
+	}
+
+	for i := 0; i < 2*adaptiveWindow; i++ {
+		a.record(shape, PdqStrategy, 40*time.Millisecond, 4000)
+	}
+	if s := a.choose(shape, MergeStrategy, candidates); s != TimsortStrategy {
+		t.Errorf("Chose %v after Pdq slowed down, want Timsort", s)
+	}
+}
+
+// TestAdaptiveWarmStart tests that an imported table drives Sort and
+// Explain on queues of the shape it describes
+func TestAdaptiveWarmStart(t *testing.T) {
+	type point struct{ x, y int }
+	points := make([]point, 3000)
+	for i := range points {
+		points[i] = point{rand.Intn(1000), rand.Intn(1000)}
+	}
+	less := func(a, b point) bool { return a.x < b.x }
+
+	table := `[
+		{"dataType": "Struct", "sizeBucket": 12, "presortedBucket": 2, "strategy": "Pdq", "runs": 10, "nanosPerElement": 90},
+		{"dataType": "Struct", "sizeBucket": 12, "presortedBucket": 2, "strategy": "Timsort", "runs": 10, "nanosPerElement": 40}
+	]`
+	a := NewAdaptiveSelector(0)
+	if err := json.Unmarshal([]byte(table), a); err != nil {
+		t.Fatal(err)
+	}
+
+	pq := New(points, less, WithAdaptiveSelector(a), WithWorkers(1))
+	plan := pq.Explain()
+	if plan.Strategy != TimsortStrategy || plan.Rule != "fastest measured for this shape" {
+		t.Errorf("Plan %v by %q, want Timsort from the table", plan.Strategy, plan.Rule)
+	}
+	pq.Sort()
+	if a.table[sortShape{StructType, 12, 2}][TimsortStrategy].runs != 11 {
+		t.Error("Sort did not use and record Timsort")
+	}
+	if !sort.SliceIsSorted(pq.data, func(i, j int) bool { return less(pq.data[i], pq.data[j]) }) {
+		t.Error("Not sorted")
+	}
+
+	// Other shapes still follow the rules
+	if plan := New(points[:100], less, WithAdaptiveSelector(a)).Explain(); plan.Rule == "fastest measured for this shape" {
+		t.Errorf("Untrained shape used the table: %+v", plan)
+	}
+}
+
+// TestAdaptiveEligibility tests that a shared selector only hands a queue
+// strategies that apply to it, whatever other queues of its shape measured
+func TestAdaptiveEligibility(t *testing.T) {
+	a := NewAdaptiveSelector(0)
+	data := generateRandomInts(3000)
+	natural := NewInts(slices.Clone(data), WithAdaptiveSelector(a), WithWorkers(1))
+	custom := New(slices.Clone(data), func(a, b int) bool { return a < b }, WithAdaptiveSelector(a), WithWorkers(1))
+	tracked := NewInts(slices.Clone(data), WithAdaptiveSelector(a), WithWorkers(1))
+	h := tracked.PushHandle(-1)
+
+	for _, pq := range []*PQueue[int]{natural, custom, tracked} {
+		a.record(pq.shape(), RadixStrategy, time.Microsecond, pq.size)
+		a.record(pq.shape(), PdqStrategy, time.Millisecond, pq.size)
+	}
+	if plan := natural.Explain(); plan.Strategy != RadixStrategy {
+		t.Errorf("Natural queue: %v, want the measured Radix", plan.Strategy)
+	}
+
+	radixRuns := a.table[custom.shape()][RadixStrategy].runs
+	for name, pq := range map[string]*PQueue[int]{"custom": custom, "tracked": tracked} {
+		shape := pq.shape()
+		if plan := pq.Explain(); plan.Strategy != PdqStrategy {
+			t.Errorf("%s: plan %v by %q, want Pdq", name, plan.Strategy, plan.Rule)
+		}
+		pq.Sort()
+		if !sort.IntsAreSorted(pq.ToSlice()) {
+			t.Errorf("%s: not sorted", name)
+		}
+		if runs := a.table[shape][PdqStrategy].runs; runs < 2 {
+			t.Errorf("%s: sort not recorded under Pdq", name)
+		}
+	}
+	if runs := a.table[natural.shape()][RadixStrategy].runs; runs != radixRuns {
+		t.Errorf("Radix recorded %d times, want %d", runs, radixRuns)
+	}
+	if v, err := tracked.Value(h); err != nil || v != -1 {
+		t.Errorf("Value = %v, %v", v, err)
+	}
+}
+
+// TestAdaptiveJSON tests the export round trip and rejection of bad tables
+func TestAdaptiveJSON(t *testing.T) {
+	a := NewAdaptiveSelector(1)
+	for i := 0; i < 30; i++ {
+		NewFloats(generateRandomFloats(500), WithAdaptiveSelector(a)).Sort()
+		NewStrings(generateLogKeys(500), WithAdaptiveSelector(a)).Sort()
+	}
+
+	b, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewAdaptiveSelector(0)
+	if err := json.Unmarshal(b, restored); err != nil {
+		t.Fatal(err)
+	}
+	b2, _ := json.Marshal(restored)
+	var first, second []adaptiveEntry
+	json.Unmarshal(b, &first)
+	json.Unmarshal(b2, &second)
+	byKey := func(x, y adaptiveEntry) int {
+		return cmp.Or(
+			cmp.Compare(x.DataType, y.DataType),
+			cmp.Compare(x.SizeBucket, y.SizeBucket),
+			cmp.Compare(x.PresortedBucket, y.PresortedBucket),
+			cmp.Compare(x.Strategy, y.Strategy),
+		)
+	}
+	slices.SortFunc(first, byKey)
+	slices.SortFunc(second, byKey)
+	if len(first) < 2 || !slices.Equal(first, second) {
+		t.Errorf("Round trip changed the table:\n%v\n%v", first, second)
+	}
+
+	for _, bad := range []string{
+		`[{"dataType": "Tensor", "sizeBucket": 1, "strategy": "Pdq", "runs": 1}]`,
+		`[{"dataType": "Integer", "sizeBucket": 1, "strategy": "Bogo", "runs": 1}]`,
+		`[{"dataType": "Integer", "sizeBucket": 1, "strategy": "Auto", "runs": 1}]`,
+		`[{"dataType": "Integer", "sizeBucket": 1, "strategy": "Pdq", "runs": 0}]`,
+	} {
+		if err := json.Unmarshal([]byte(bad), restored); !errors.Is(err, ErrInvalidTable) {
+			t.Errorf("%s: expected ErrInvalidTable, got %v", bad, err)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{`), restored); err == nil {
+		t.Error("Expected a syntax error")
+	}
+}
+
+// TestAdaptiveCandidates tests which strategies are explored
+func TestAdaptiveCandidates(t *testing.T) {
+	ints := NewInts(generateRandomInts(30))
+	c := ints.candidates(CountingStrategy)
+	for _, want := range []SortStrategy{InsertionStrategy, RadixStrategy, CountingStrategy, PdqStrategy} {
+		if !slices.Contains(c, want) {
+			t.Errorf("Small integers: %v missing from %v", want, c)
+		}
+	}
+
+	wide := NewInts(generateRandomInts(5000))
+	if c := wide.candidates(RadixStrategy); slices.Contains(c, CountingStrategy) {
+		t.Errorf("Wide integer range explores counting sort: %v", c)
+	}
+
+	ints.PushHandle(5)
+	if c := ints.candidates(PdqStrategy); slices.Contains(c, RadixStrategy) {
+		t.Errorf("Tracked queue explores key-based sorts: %v", c)
+	}
+
+	large := New(generateRandomInts(20000), func(a, b int) bool { return a > b }, WithWorkers(4))
+	c = large.candidates(QuickStrategy)
+	if slices.Contains(c, InsertionStrategy) || slices.Contains(c, RadixStrategy) {
+		t.Errorf("Large custom order: %v", c)
+	}
+	if !slices.Contains(c, ParallelMergeStrategy) || c[len(c)-1] != QuickStrategy {
+		t.Errorf("Large custom order misses parallel or rule choice: %v", c)
+	}
+}
+
+// TestAdaptiveConcurrent tests sharing a selector between goroutines
+func TestAdaptiveConcurrent(t *testing.T) {
+	a := NewAdaptiveSelector(0.5)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				pq := NewInts(generateRandomInts(1000), WithAdaptiveSelector(a))
+				pq.Sort()
+				if !sort.IntsAreSorted(pq.ToSlice()) {
+					t.Error("Not sorted")
+					return
+				}
+				if _, err := json.Marshal(a); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
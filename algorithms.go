@@ -2,7 +2,7 @@ package pqueue
 
 import (
 	"math"
-	"reflect"
+	"math/bits"
 )
 
 // insertionSort performs insertion sort on the queue data
@@ -39,10 +39,10 @@ func (pq *PQueue[T]) partition(low, high int) int {
 	for j := low; j < high; j++ {
 		if pq.less(pq.data[j], pivot) || (!pq.less(pivot, pq.data[j]) && !pq.less(pq.data[j], pivot)) {
 			i++
-			pq.data[i], pq.data[j] = pq.data[j], pq.data[i]
+			pq.swap(i, j)
 		}
 	}
-	pq.data[i+1], pq.data[high] = pq.data[high], pq.data[i+1]
+	pq.swap(i+1, high)
 	return i + 1
 }
 
@@ -149,7 +149,7 @@ func (pq *PQueue[T]) heapSortRange(low, high int) {
 
 	// Extract elements from heap
 	for i := size - 1; i > 0; i-- {
-		pq.data[low], pq.data[low+i] = pq.data[low+i], pq.data[low]
+		pq.swap(low, low+i)
 		pq.heapify(low, i, low)
 	}
 }
@@ -168,242 +168,50 @@ func (pq *PQueue[T]) heapify(base, size, root int) {
 	}
 
 	if largest != root {
-		pq.data[root], pq.data[largest] = pq.data[largest], pq.data[root]
+		pq.swap(root, largest)
 		pq.heapify(base, size, largest)
 	}
 }
 
-// timsort performs a simplified version of Timsort
-func (pq *PQueue[T]) timsort() {
-	minMerge := 32
-
-	if pq.size <= minMerge {
-		pq.insertionSort()
-		return
-	}
-
-	// Find runs and merge them
-	runs := pq.findRuns()
-	pq.mergeRuns(runs)
-}
-
-func (pq *PQueue[T]) findRuns() []int {
-	runs := []int{0}
-	i := 0
-
-	for i < pq.size-1 {
-		start := i
-
-		// Find ascending or descending run
-		if pq.less(pq.data[i], pq.data[i+1]) {
-			// Ascending run
-			for i < pq.size-1 && pq.less(pq.data[i], pq.data[i+1]) {
-				i++
-			}
-		} else {
-			// Descending run - reverse it
-			for i < pq.size-1 && (pq.less(pq.data[i+1], pq.data[i]) || (!pq.less(pq.data[i], pq.data[i+1]) && !pq.less(pq.data[i+1], pq.data[i]))) {
-				i++
-			}
-			pq.reverse(start, i)
-		}
-
-		i++
-		runs = append(runs, i)
-	}
-
-	if runs[len(runs)-1] != pq.size {
-		runs = append(runs, pq.size)
-	}
-
-	return runs
-}
-
-func (pq *PQueue[T]) mergeRuns(runs []int) {
-	temp := make([]T, pq.size)
-
-	for len(runs) > 2 {
-		newRuns := []int{runs[0]}
-
-		for i := 1; i < len(runs)-1; i += 2 {
-			left := runs[i-1]
-			mid := runs[i] - 1
-			right := runs[i+1] - 1
-
-			pq.merge(left, mid, right, temp)
-			newRuns = append(newRuns, runs[i+1])
-		}
-
-		// Handle odd number of runs
-		if len(runs)%2 == 0 {
-			newRuns = append(newRuns, runs[len(runs)-1])
-		}
-
-		runs = newRuns
-	}
-}
-
 func (pq *PQueue[T]) reverse(start, end int) {
 	for start < end {
-		pq.data[start], pq.data[end] = pq.data[end], pq.data[start]
+		pq.swap(start, end)
 		start++
 		end--
 	}
 }
 
-// radixSort performs radix sort for integer types
+// radixSort performs an LSD radix sort for integer and floating-point data
+// in its natural order, falling back to pdqsort for anything else
 func (pq *PQueue[T]) radixSort() {
-	// This is a simplified implementation that works with reflect
-	// In practice, you'd want type-specific implementations for better performance
-	if pq.dataType != IntegerType {
-		pq.quickSort()
+	if s, ok := pq.integers(); ok {
+		s.radixSort()
 		return
 	}
-
-	// Get the maximum value to determine number of digits
-	maxVal := pq.getMaxInt()
-	if maxVal <= 0 {
+	if s, ok := pq.floats(); ok {
+		s.radixSort(pq.opts.nanOrder)
 		return
 	}
-
-	// Do counting sort for every digit
-	for exp := 1; maxVal/exp > 0; exp *= 10 {
-		pq.countingSortByDigit(exp)
-	}
-}
-
-func (pq *PQueue[T]) getMaxInt() int {
-	if pq.size == 0 {
-		return 0
-	}
-
-	max := 0
-	for i := 0; i < pq.size; i++ {
-		val := reflect.ValueOf(pq.data[i])
-		if val.Kind() == reflect.Int || val.Kind() == reflect.Int64 || val.Kind() == reflect.Int32 {
-			intVal := int(val.Int())
-			if intVal > max {
-				max = intVal
-			}
-		}
-	}
-	return max
-}
-
-func (pq *PQueue[T]) countingSortByDigit(exp int) {
-	output := make([]T, pq.size)
-	count := make([]int, 10)
-
-	// Count occurrences of each digit
-	for i := 0; i < pq.size; i++ {
-		val := reflect.ValueOf(pq.data[i])
-		digit := (int(val.Int()) / exp) % 10
-		count[digit]++
-	}
-
-	// Change count[i] to actual position
-	for i := 1; i < 10; i++ {
-		count[i] += count[i-1]
-	}
-
-	// Build output array
-	for i := pq.size - 1; i >= 0; i-- {
-		val := reflect.ValueOf(pq.data[i])
-		digit := (int(val.Int()) / exp) % 10
-		output[count[digit]-1] = pq.data[i]
-		count[digit]--
-	}
-
-	// Copy output array to data
-	copy(pq.data[:pq.size], output)
+	pq.pdqsort()
 }
 
-// countingSort performs counting sort for small integer ranges
+// countingSort performs counting sort for small range integers. Ranges
+// too wide to count are radix sorted instead.
 func (pq *PQueue[T]) countingSort() {
-	if pq.dataType != IntegerType {
-		pq.quickSort()
-		return
-	}
-
-	minVal, maxVal := pq.getMinMaxInt()
-	if maxVal-minVal > 10000 { // Don't use counting sort for large ranges
-		pq.quickSort()
+	s, ok := pq.integers()
+	if !ok {
+		pq.pdqsort()
 		return
 	}
-
-	count := make([]int, maxVal-minVal+1)
-
-	// Count each element
-	for i := 0; i < pq.size; i++ {
-		val := reflect.ValueOf(pq.data[i])
-		index := int(val.Int()) - minVal
-		count[index]++
-	}
-
-	// Reconstruct the array
-	pos := 0
-	for i := 0; i < len(count); i++ {
-		for count[i] > 0 {
-			val := reflect.ValueOf(minVal + i)
-			pq.data[pos] = val.Interface().(T)
-			pos++
-			count[i]--
-		}
-	}
+	s.countingSort()
 }
 
-func (pq *PQueue[T]) getMinMaxInt() (int, int) {
-	if pq.size == 0 {
-		return 0, 0
-	}
-
-	val := reflect.ValueOf(pq.data[0])
-	min, max := int(val.Int()), int(val.Int())
-
-	for i := 1; i < pq.size; i++ {
-		val := reflect.ValueOf(pq.data[i])
-		intVal := int(val.Int())
-		if intVal < min {
-			min = intVal
-		}
-		if intVal > max {
-			max = intVal
-		}
-	}
-
-	return min, max
-}
-
-// isNearlySorted checks if the data is nearly sorted
-func (pq *PQueue[T]) isNearlySorted() bool {
-	if pq.size <= 1 {
-		return true
-	}
-
-	inversions := 0
-	threshold := pq.size / 10 // Allow up to 10% inversions
-	if threshold < 1 {
-		threshold = 1 // Allow at least 1 inversion for small arrays
-	}
-
-	for i := 0; i < pq.size-1; i++ {
-		if pq.less(pq.data[i+1], pq.data[i]) {
-			inversions++
-			if inversions > threshold {
-				return false
-			}
-		}
-	}
-
-	return true
-}
-
-// hasSmallRange checks if integer data has a small range
-func (pq *PQueue[T]) hasSmallRange() bool {
-	if pq.dataType != IntegerType || pq.size == 0 {
-		return false
-	}
-
-	min, max := pq.getMinMaxInt()
-	return (max - min) <= 1000 // Consider small if range is <= 1000
+// isNearlySorted checks if insertion sort stays within O(n log n) on the
+// data. Its cost grows with the number of inversions, so the fraction
+// sampled from m pairs, plus a margin for those the sample missed, must
+// stay below n log n pairs.
+func (pq *PQueue[T]) isNearlySorted(m int) bool {
+	n := float64(pq.size)
+	bound := (pq.sampleInversions(m) + 3/float64(m)) * n * (n - 1) / 2
+	return bound <= n*float64(bits.Len(uint(pq.size)))
 }
@@ -23,6 +23,7 @@ func TestAllSortingStrategies(t *testing.T) {
 		{"Merge", MergeStrategy},
 		{"Introsort", IntrosortStrategy},
 		{"Timsort", TimsortStrategy},
+		{"Pdq", PdqStrategy},
 	}
 
 	for _, s := range strategies {
@@ -305,6 +306,12 @@ func TestStability(t *testing.T) {
 		return a.Value < b.Value
 	})
 
+	// New arranges the items in heap order, so stability is measured
+	// against the order the queue holds them in before sorting
+	for i := 0; i < pq.size; i++ {
+		pq.data[i].Index = i
+	}
+
 	// Test with merge sort (stable)
 	pq.SortWithStrategy(MergeStrategy)
 	result := pq.ToSlice()
@@ -327,8 +334,8 @@ func TestStability(t *testing.T) {
 
 // TestConcurrentAccess tests thread safety considerations
 func TestConcurrentAccess(t *testing.T) {
-	// Note: PQueue is not thread-safe by design, but we test that
-	// concurrent read operations don't cause data races
+	// Note: PQueue is not thread-safe by design (ConcurrentPQueue is), but
+	// we test that concurrent read operations don't cause data races
 	data := make([]int, 1000)
 	for i := range data {
 		data[i] = rand.Intn(1000)
@@ -372,6 +379,7 @@ func TestMemoryUsage(t *testing.T) {
 		MergeStrategy,
 		IntrosortStrategy,
 		TimsortStrategy,
+		PdqStrategy,
 	}
 
 	for _, strategy := range strategies {
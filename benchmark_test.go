@@ -3,6 +3,7 @@ package pqueue
 import (
 	"fmt"
 	"math/rand"
+	"slices"
 	"sort"
 	"testing"
 	"time"
@@ -44,6 +45,45 @@ func BenchmarkPQueueVsStandardSort(b *testing.B) {
 	}
 }
 
+// BenchmarkSortSliceVsSlicesSort compares the in-place slice functions with
+// the slices package
+func BenchmarkSortSliceVsSlicesSort(b *testing.B) {
+	sizes := []int{100, 1000, 10000, 100000}
+
+	for _, size := range sizes {
+		data := generateRandomInts(size)
+		testData := make([]int, size)
+
+		b.Run(fmt.Sprintf("SortOrdered_Size_%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(testData, data)
+				SortOrdered(testData)
+			}
+		})
+
+		b.Run(fmt.Sprintf("SortSlice_Size_%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(testData, data)
+				SortSlice(testData, func(a, b int) bool { return a < b })
+			}
+		})
+
+		b.Run(fmt.Sprintf("SlicesSort_Size_%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(testData, data)
+				slices.Sort(testData)
+			}
+		})
+
+		b.Run(fmt.Sprintf("SlicesSortFunc_Size_%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(testData, data)
+				slices.SortFunc(testData, func(a, b int) int { return a - b })
+			}
+		})
+	}
+}
+
 // BenchmarkSortingStrategies benchmarks different sorting strategies
 func BenchmarkSortingStrategies(b *testing.B) {
 	strategies := []struct {
@@ -114,6 +154,35 @@ func BenchmarkSpecializedSorts(b *testing.B) {
 	})
 }
 
+// BenchmarkStringStrategies compares MSD radix sort with comparison sorts
+// on keys sharing long prefixes
+func BenchmarkStringStrategies(b *testing.B) {
+	strategies := []struct {
+		name     string
+		strategy SortStrategy
+	}{
+		{"MSDRadix", MSDRadixStrategy},
+		{"Introsort", IntrosortStrategy},
+		{"Timsort", TimsortStrategy},
+	}
+
+	for _, size := range []int{1000, 100000} {
+		data := generateLogKeys(size)
+		testData := make([]string, size)
+
+		for _, s := range strategies {
+			b.Run(fmt.Sprintf("%s_Size_%d", s.name, size), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					copy(testData, data)
+					pq := sliceQueue(testData, func(a, b string) bool { return a < b })
+					pq.natural = true
+					pq.SortWithStrategy(s.strategy)
+				}
+			})
+		}
+	}
+}
+
 // BenchmarkDataTypes benchmarks different data types
 func BenchmarkDataTypes(b *testing.B) {
 	size := 1000
@@ -212,6 +281,67 @@ func BenchmarkPriorityQueueOperations(b *testing.B) {
 	})
 }
 
+// BenchmarkConcurrentQueues compares a mutex-guarded queue with the
+// sharded MultiQueue under 64 goroutines per CPU
+func BenchmarkConcurrentQueues(b *testing.B) {
+	less := func(a, b int) bool { return a < b }
+
+	b.Run("ConcurrentPQueue", func(b *testing.B) {
+		q := NewConcurrent(generateRandomInts(10000), less)
+
+		b.SetParallelism(64)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				q.Push(rand.Intn(100000))
+				q.TryPop()
+			}
+		})
+	})
+
+	b.Run("MultiQueue", func(b *testing.B) {
+		q := NewMultiQueue(less)
+		for _, v := range generateRandomInts(10000) {
+			q.Push(v)
+		}
+
+		b.SetParallelism(64)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				q.Push(rand.Intn(100000))
+				q.TryPop()
+			}
+		})
+	})
+}
+
+// BenchmarkHeapKinds benchmarks push/pop cycles on each heap backend
+func BenchmarkHeapKinds(b *testing.B) {
+	kinds := []struct {
+		name string
+		kind HeapKind
+	}{
+		{"Binary", BinaryHeap},
+		{"Quaternary", QuaternaryHeap},
+		{"Pairing", PairingHeap},
+		{"Fibonacci", FibonacciHeap},
+		{"Auto", AutoHeap},
+	}
+
+	for _, k := range kinds {
+		b.Run(k.name, func(b *testing.B) {
+			pq := NewInts(generateRandomInts(10000), WithHeapKind(k.kind))
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				pq.Push(rand.Intn(100000))
+				pq.Pop()
+			}
+		})
+	}
+}
+
 // BenchmarkWorstCaseScenarios benchmarks worst-case scenarios
 func BenchmarkWorstCaseScenarios(b *testing.B) {
 	size := 1000
@@ -273,6 +403,131 @@ func BenchmarkWorstCaseScenarios(b *testing.B) {
 	})
 }
 
+// BenchmarkPatternedInputs compares quicksort variants on inputs that
+// defeat naive pivot choices. A custom less keeps key-based sorts out.
+func BenchmarkPatternedInputs(b *testing.B) {
+	strategies := []struct {
+		name     string
+		strategy SortStrategy
+	}{
+		{"Quick", QuickStrategy},
+		{"Introsort", IntrosortStrategy},
+		{"Pdq", PdqStrategy},
+	}
+
+	size := 5000
+	inputs := patternInputs(size)
+	testData := make([]int, size)
+	for _, name := range []string{"sorted", "reversed", "equal", "organ pipe", "random"} {
+		for _, s := range strategies {
+			b.Run(fmt.Sprintf("%s_%s", s.name, name), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					copy(testData, inputs[name])
+					pq := sliceQueue(testData, func(a, b int) bool { return a < b })
+					pq.SortWithStrategy(s.strategy)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkTimsort compares the full Timsort with the earlier pairwise
+// run merger on inputs with and without existing order
+func BenchmarkTimsort(b *testing.B) {
+	size := 100000
+	inputs := map[string][]int{}
+	for _, name := range []string{"sorted", "reversed", "few values", "random"} {
+		inputs[name] = patternInputs(size)[name]
+	}
+
+	// Sorted blocks of 1000 in random block order
+	runs := make([]int, size)
+	for i := range runs {
+		runs[i] = i
+	}
+	blocks := size / 1000
+	rand.Shuffle(blocks, func(i, j int) {
+		for k := 0; k < 1000; k++ {
+			runs[i*1000+k], runs[j*1000+k] = runs[j*1000+k], runs[i*1000+k]
+		}
+	})
+	inputs["sorted blocks"] = runs
+
+	variants := []struct {
+		name string
+		sort func(pq *PQueue[int])
+	}{
+		{"Legacy", (*PQueue[int]).legacyTimsort},
+		{"Timsort", (*PQueue[int]).timsort},
+	}
+
+	testData := make([]int, size)
+	for _, name := range []string{"sorted", "reversed", "sorted blocks", "few values", "random"} {
+		for _, v := range variants {
+			b.Run(fmt.Sprintf("%s_%s", v.name, name), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					copy(testData, inputs[name])
+					v.sort(sliceQueue(testData, func(a, b int) bool { return a < b }))
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkParallelMergeSort compares the parallel sort with sequential
+// comparison sorts on a large input
+func BenchmarkParallelMergeSort(b *testing.B) {
+	strategies := []struct {
+		name     string
+		strategy SortStrategy
+	}{
+		{"Timsort", TimsortStrategy},
+		{"Pdq", PdqStrategy},
+		{"ParallelMerge", ParallelMergeStrategy},
+	}
+
+	size := 1 << 20
+	data := generateRandomInts(size)
+	testData := make([]int, size)
+	for _, s := range strategies {
+		b.Run(s.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(testData, data)
+				pq := sliceQueue(testData, func(a, b int) bool { return a < b })
+				pq.SortWithStrategy(s.strategy)
+			}
+		})
+	}
+}
+
+// BenchmarkSelectK compares heap-select and quickselect around the k/n
+// ratio where chooseSelectMethod switches between them
+func BenchmarkSelectK(b *testing.B) {
+	size := 1 << 16
+	data := generateRandomInts(size)
+	testData := make([]int, size)
+	for _, ratio := range []int{16, partialHeapRatio, 256} {
+		k := size / ratio
+		for _, m := range []struct {
+			name   string
+			method selectMethod
+		}{{"Heap", heapSelectMethod}, {"Quick", quickSelectMethod}} {
+			b.Run(fmt.Sprintf("n/k=%d/%s", ratio, m.name), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					copy(testData, data)
+					pq := sliceQueue(testData, func(a, b int) bool { return a < b })
+					if m.method == heapSelectMethod {
+						pq.heapSelect(k, testData[k:], true)
+					} else {
+						pq.nthElement(k - 1)
+						pq.pdqsortFirst(k - 1)
+					}
+				}
+			})
+		}
+	}
+}
+
 // BenchmarkMemoryAllocation benchmarks memory allocation patterns
 func BenchmarkMemoryAllocation(b *testing.B) {
 	b.Run("SmallArrays", func(b *testing.B) {
@@ -378,3 +633,74 @@ func BenchmarkCustomTypes(b *testing.B) {
 		pq.Sort()
 	}
 }
+
+// legacyTimsort is the pairwise run merger that TimsortStrategy used
+// before the full Timsort, kept as a benchmark baseline
+func (pq *PQueue[T]) legacyTimsort() {
+	minMerge := 32
+
+	if pq.size <= minMerge {
+		pq.insertionSort()
+		return
+	}
+
+	// Find runs and merge them
+	runs := pq.legacyFindRuns()
+	pq.legacyMergeRuns(runs)
+}
+
+func (pq *PQueue[T]) legacyFindRuns() []int {
+	runs := []int{0}
+	i := 0
+
+	for i < pq.size-1 {
+		start := i
+
+		// Find ascending or descending run
+		if pq.less(pq.data[i], pq.data[i+1]) {
+			// Ascending run
+			for i < pq.size-1 && pq.less(pq.data[i], pq.data[i+1]) {
+				i++
+			}
+		} else {
+			// Descending run - reverse it
+			for i < pq.size-1 && (pq.less(pq.data[i+1], pq.data[i]) || (!pq.less(pq.data[i], pq.data[i+1]) && !pq.less(pq.data[i+1], pq.data[i]))) {
+				i++
+			}
+			pq.reverse(start, i)
+		}
+
+		i++
+		runs = append(runs, i)
+	}
+
+	if runs[len(runs)-1] != pq.size {
+		runs = append(runs, pq.size)
+	}
+
+	return runs
+}
+
+func (pq *PQueue[T]) legacyMergeRuns(runs []int) {
+	temp := make([]T, pq.size)
+
+	for len(runs) > 2 {
+		newRuns := []int{runs[0]}
+
+		for i := 1; i < len(runs)-1; i += 2 {
+			left := runs[i-1]
+			mid := runs[i] - 1
+			right := runs[i+1] - 1
+
+			pq.merge(left, mid, right, temp)
+			newRuns = append(newRuns, runs[i+1])
+		}
+
+		// Handle odd number of runs
+		if len(runs)%2 == 0 {
+			newRuns = append(newRuns, runs[len(runs)-1])
+		}
+
+		runs = newRuns
+	}
+}
@@ -0,0 +1,79 @@
+package pqueue
+
+// BoundedPQueue keeps only the k best elements pushed into it, where better
+// means smaller according to less. The elements are held in an inverted
+// heap so the worst of them is at the root and can be evicted in O(log k).
+type BoundedPQueue[T any] struct {
+	queue    *PQueue[T] // ordered by the inverse of less
+	less     func(T, T) bool
+	capacity int
+	opts     []Option
+}
+
+// NewBounded creates an empty BoundedPQueue that retains at most k elements
+func NewBounded[T any](k int, less func(T, T) bool, opts ...Option) *BoundedPQueue[T] {
+	if k < 0 {
+		k = 0
+	}
+	return &BoundedPQueue[T]{
+		queue:    New(make([]T, 0, k), func(a, b T) bool { return less(b, a) }, opts...),
+		less:     less,
+		capacity: k,
+		opts:     opts,
+	}
+}
+
+// Size returns the number of retained elements
+func (b *BoundedPQueue[T]) Size() int {
+	return b.queue.Size()
+}
+
+// Cap returns the maximum number of retained elements
+func (b *BoundedPQueue[T]) Cap() int {
+	return b.capacity
+}
+
+// IsEmpty returns true if no element is retained
+func (b *BoundedPQueue[T]) IsEmpty() bool {
+	return b.queue.IsEmpty()
+}
+
+// Push offers an element to the queue. If the queue is full, the worst of
+// the retained elements and item is evicted and returned with ok set to
+// true; that may be item itself. Ties keep the element already retained.
+func (b *BoundedPQueue[T]) Push(item T) (evicted T, ok bool) {
+	if b.queue.Size() < b.capacity {
+		b.queue.Push(item)
+		return evicted, false
+	}
+	if b.capacity == 0 {
+		return item, true
+	}
+
+	worst, _ := b.queue.Peek()
+	if !b.less(item, worst) {
+		return item, true
+	}
+	b.queue.Pop()
+	b.queue.Push(item)
+	return worst, true
+}
+
+// Worst returns the retained element that the next overflow would evict
+func (b *BoundedPQueue[T]) Worst() (T, error) {
+	return b.queue.Peek()
+}
+
+// Sorted returns the retained elements from best to worst. The queue is
+// left unchanged.
+func (b *BoundedPQueue[T]) Sorted() []T {
+	return b.SortedWithStrategy(AutoStrategy)
+}
+
+// SortedWithStrategy returns the retained elements from best to worst,
+// sorted with a specific strategy
+func (b *BoundedPQueue[T]) SortedWithStrategy(strategy SortStrategy) []T {
+	pq := New(b.queue.ToSlice(), b.less, b.opts...)
+	pq.SortWithStrategy(strategy)
+	return pq.ToSlice()
+}
@@ -0,0 +1,101 @@
+package pqueue
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// TestBoundedTopK tests that only the k best elements are retained
+func TestBoundedTopK(t *testing.T) {
+	for _, hk := range allHeapKinds {
+		t.Run(hk.name, func(t *testing.T) {
+			const k = 50
+			b := NewBounded(k, func(a, b int) bool { return a < b }, WithHeapKind(hk.kind))
+
+			data := make([]int, 5000)
+			evictions := 0
+			for i := range data {
+				data[i] = rand.Intn(100000)
+				if _, ok := b.Push(data[i]); ok {
+					evictions++
+				}
+			}
+
+			if b.Size() != k {
+				t.Fatalf("Expected size %d, got %d", k, b.Size())
+			}
+			if evictions != len(data)-k {
+				t.Errorf("Expected %d evictions, got %d", len(data)-k, evictions)
+			}
+
+			sort.Ints(data)
+			if got := b.Sorted(); !reflect.DeepEqual(got, data[:k]) {
+				t.Errorf("Sorted() = %v, want %v", got, data[:k])
+			}
+			if worst, err := b.Worst(); err != nil || worst != data[k-1] {
+				t.Errorf("Worst() = %d, %v, want %d, nil", worst, err, data[k-1])
+			}
+		})
+	}
+}
+
+// TestBoundedEviction tests the element reported on overflow
+func TestBoundedEviction(t *testing.T) {
+	b := NewBounded(2, func(a, b int) bool { return a < b })
+
+	if _, ok := b.Push(5); ok {
+		t.Error("Push below capacity reported an eviction")
+	}
+	b.Push(3)
+
+	if v, ok := b.Push(9); !ok || v != 9 {
+		t.Errorf("Push(9) = %d, %v, want 9, true", v, ok)
+	}
+	if v, ok := b.Push(5); !ok || v != 5 {
+		t.Errorf("Push(5) on a tie = %d, %v, want 5, true", v, ok)
+	}
+	if v, ok := b.Push(1); !ok || v != 5 {
+		t.Errorf("Push(1) = %d, %v, want 5, true", v, ok)
+	}
+	if got := b.Sorted(); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Errorf("Sorted() = %v, want [1 3]", got)
+	}
+
+	// Sorted must not disturb the queue
+	if v, ok := b.Push(2); !ok || v != 3 {
+		t.Errorf("Push(2) = %d, %v, want 3, true", v, ok)
+	}
+
+	zero := NewBounded(0, func(a, b int) bool { return a < b })
+	if v, ok := zero.Push(4); !ok || v != 4 || !zero.IsEmpty() {
+		t.Errorf("Push on zero capacity = %d, %v, want 4, true", v, ok)
+	}
+}
+
+// TestBoundedSortedWithStrategy tests Sorted with every strategy and a
+// descending order, which keeps the largest elements
+func TestBoundedSortedWithStrategy(t *testing.T) {
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = rand.Intn(1000)
+	}
+	want := append([]int(nil), data...)
+	sort.Sort(sort.Reverse(sort.IntSlice(want)))
+	want = want[:100]
+
+	strategies := []SortStrategy{
+		AutoStrategy, InsertionStrategy, QuickStrategy, MergeStrategy,
+		IntrosortStrategy, TimsortStrategy, PdqStrategy,
+	}
+	for _, strategy := range strategies {
+		b := NewBounded(100, func(a, b int) bool { return a > b })
+		for _, v := range data {
+			b.Push(v)
+		}
+		if got := b.SortedWithStrategy(strategy); !reflect.DeepEqual(got, want) {
+			t.Errorf("Strategy %v: got %v, want %v", strategy, got, want)
+		}
+	}
+}
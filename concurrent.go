@@ -0,0 +1,122 @@
+package pqueue
+
+import (
+	"context"
+	"sync"
+)
+
+// ConcurrentPQueue is a PQueue that is safe for use by multiple goroutines.
+// PopWait blocks until an element is available, the context is done, or
+// the queue is closed.
+type ConcurrentPQueue[T any] struct {
+	mu      sync.Mutex
+	queue   *PQueue[T]
+	ready   chan struct{} // closed to wake waiters when an element arrives or the queue closes
+	waiters int
+	closed  bool
+}
+
+// NewConcurrent creates a new ConcurrentPQueue with the given data and
+// comparison function
+func NewConcurrent[T any](data []T, less func(T, T) bool, opts ...Option) *ConcurrentPQueue[T] {
+	return &ConcurrentPQueue[T]{
+		queue: New(data, less, opts...),
+		ready: make(chan struct{}),
+	}
+}
+
+// Size returns the number of elements in the queue
+func (q *ConcurrentPQueue[T]) Size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.queue.Size()
+}
+
+// IsEmpty returns true if the queue is empty
+func (q *ConcurrentPQueue[T]) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Push adds an element to the queue and wakes blocked PopWait callers.
+// It returns ErrClosed once the queue has been closed.
+func (q *ConcurrentPQueue[T]) Push(item T) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+
+	q.queue.Push(item)
+	if q.waiters > 0 {
+		close(q.ready)
+		q.ready = make(chan struct{})
+	}
+	return nil
+}
+
+// Peek returns the smallest element without removing it
+func (q *ConcurrentPQueue[T]) Peek() (T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.queue.Peek()
+}
+
+// TryPop removes and returns the smallest element without blocking. It
+// returns ErrEmpty if the queue is empty, or ErrClosed if it is also closed.
+func (q *ConcurrentPQueue[T]) TryPop() (T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.queue.IsEmpty() && q.closed {
+		var zero T
+		return zero, ErrClosed
+	}
+	return q.queue.Pop()
+}
+
+// PopWait removes and returns the smallest element, blocking until one is
+// available. It returns ctx.Err() if the context is done first, and
+// ErrClosed once the queue is closed and drained.
+func (q *ConcurrentPQueue[T]) PopWait(ctx context.Context) (T, error) {
+	var zero T
+	q.mu.Lock()
+	for {
+		if !q.queue.IsEmpty() {
+			item, err := q.queue.Pop()
+			q.mu.Unlock()
+			return item, err
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return zero, ErrClosed
+		}
+
+		ready := q.ready
+		q.waiters++
+		q.mu.Unlock()
+
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			q.mu.Lock()
+			q.waiters--
+			q.mu.Unlock()
+			return zero, ctx.Err()
+		}
+
+		q.mu.Lock()
+		q.waiters--
+	}
+}
+
+// Close stops the queue from accepting new elements and wakes all blocked
+// PopWait callers. Elements already queued can still be popped; afterwards
+// pops return ErrClosed. Closing an already closed queue has no effect.
+func (q *ConcurrentPQueue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	close(q.ready)
+}
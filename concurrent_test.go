@@ -0,0 +1,177 @@
+package pqueue
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrentPushPop tests many producers and consumers sharing a queue
+func TestConcurrentPushPop(t *testing.T) {
+	q := NewConcurrent(nil, func(a, b int) bool { return a < b })
+
+	const producers, perProducer = 8, 500
+	var producersDone sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		producersDone.Add(1)
+		go func(p int) {
+			defer producersDone.Done()
+			for i := 0; i < perProducer; i++ {
+				if err := q.Push(p*perProducer + i); err != nil {
+					t.Errorf("Push: %v", err)
+				}
+			}
+		}(p)
+	}
+
+	var mu sync.Mutex
+	var popped []int
+	var consumersDone sync.WaitGroup
+	for c := 0; c < 8; c++ {
+		consumersDone.Add(1)
+		go func() {
+			defer consumersDone.Done()
+			for {
+				v, err := q.PopWait(context.Background())
+				if errors.Is(err, ErrClosed) {
+					return
+				}
+				if err != nil {
+					t.Errorf("PopWait: %v", err)
+					return
+				}
+				mu.Lock()
+				popped = append(popped, v)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	producersDone.Wait()
+	q.Close()
+	consumersDone.Wait()
+
+	if len(popped) != producers*perProducer {
+		t.Fatalf("Popped %d elements, want %d", len(popped), producers*perProducer)
+	}
+	sort.Ints(popped)
+	for i, v := range popped {
+		if v != i {
+			t.Fatalf("Element %d missing or duplicated", i)
+		}
+	}
+}
+
+// TestConcurrentPopWaitBlocks tests that PopWait wakes up on Push
+func TestConcurrentPopWaitBlocks(t *testing.T) {
+	q := NewConcurrent(nil, func(a, b int) bool { return a < b })
+
+	result := make(chan int)
+	go func() {
+		v, err := q.PopWait(context.Background())
+		if err != nil {
+			t.Errorf("PopWait: %v", err)
+		}
+		result <- v
+	}()
+
+	select {
+	case v := <-result:
+		t.Fatalf("PopWait returned %d before any Push", v)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	q.Push(42)
+	select {
+	case v := <-result:
+		if v != 42 {
+			t.Errorf("PopWait = %d, want 42", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PopWait did not wake up after Push")
+	}
+}
+
+// TestConcurrentPopWaitCancel tests context cancellation of PopWait
+func TestConcurrentPopWaitCancel(t *testing.T) {
+	q := NewConcurrent(nil, func(a, b int) bool { return a < b })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, err := q.PopWait(ctx)
+		errs <- err
+	}()
+
+	cancel()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PopWait did not return after cancellation")
+	}
+
+	// The queue must still work for later callers
+	q.Push(7)
+	if v, err := q.TryPop(); err != nil || v != 7 {
+		t.Errorf("TryPop = %d, %v, want 7, nil", v, err)
+	}
+}
+
+// TestConcurrentClose tests that Close wakes all waiters and rejects pushes
+func TestConcurrentClose(t *testing.T) {
+	q := NewConcurrent([]int{2, 1}, func(a, b int) bool { return a < b })
+
+	// Elements queued before Close can still be drained
+	q.Close()
+	q.Close()
+	if err := q.Push(3); !errors.Is(err, ErrClosed) {
+		t.Errorf("Push after Close: got %v, want ErrClosed", err)
+	}
+	for _, want := range []int{1, 2} {
+		if v, err := q.PopWait(context.Background()); err != nil || v != want {
+			t.Errorf("PopWait = %d, %v, want %d, nil", v, err, want)
+		}
+	}
+	if _, err := q.TryPop(); !errors.Is(err, ErrClosed) {
+		t.Errorf("TryPop after drain: got %v, want ErrClosed", err)
+	}
+
+	// Blocked waiters are released with ErrClosed
+	q = NewConcurrent(nil, func(a, b int) bool { return a < b })
+	errs := make(chan error, 5)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			_, err := q.PopWait(context.Background())
+			errs <- err
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	q.Close()
+	for i := 0; i < cap(errs); i++ {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, ErrClosed) {
+				t.Errorf("Expected ErrClosed, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Waiter was not released by Close")
+		}
+	}
+}
+
+// TestConcurrentTryPopEmpty tests TryPop on an empty open queue
+func TestConcurrentTryPopEmpty(t *testing.T) {
+	q := NewConcurrent(nil, func(a, b int) bool { return a < b })
+	if _, err := q.TryPop(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Expected ErrEmpty, got %v", err)
+	}
+	if !q.IsEmpty() {
+		t.Error("Expected queue to be empty")
+	}
+}
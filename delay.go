@@ -0,0 +1,169 @@
+package pqueue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is the time source used by DelayQueue. Tests can supply a fake
+// implementation through WithClock to avoid real sleeping.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock backed by the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// DelayQueue holds elements that become poppable only once their ready
+// time has passed. It is safe for use by multiple goroutines.
+type DelayQueue[T any] struct {
+	mu      sync.Mutex
+	queue   *PQueue[delayed[T]]
+	clock   Clock
+	seq     uint64
+	wake    chan struct{} // closed to wake waiters when the earliest ready time changes
+	waiters int
+}
+
+// delayed is an element of a DelayQueue with its ready time. seq keeps
+// elements with equal ready times in insertion order.
+type delayed[T any] struct {
+	value T
+	at    time.Time
+	seq   uint64
+}
+
+// NewDelayQueue creates an empty DelayQueue. It uses the system clock
+// unless WithClock is given.
+func NewDelayQueue[T any](opts ...Option) *DelayQueue[T] {
+	o := newOptions(opts)
+	clock := o.clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+
+	return &DelayQueue[T]{
+		queue: New(nil, func(a, b delayed[T]) bool {
+			if !a.at.Equal(b.at) {
+				return a.at.Before(b.at)
+			}
+			return a.seq < b.seq
+		}, opts...),
+		clock: clock,
+		wake:  make(chan struct{}),
+	}
+}
+
+// Size returns the number of elements, ready or not
+func (q *DelayQueue[T]) Size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.queue.Size()
+}
+
+// IsEmpty returns true if the queue is empty
+func (q *DelayQueue[T]) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Push adds an element that becomes ready at the given time
+func (q *DelayQueue[T]) Push(item T, at time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	head, err := q.queue.Peek()
+	q.seq++
+	q.queue.Push(delayed[T]{value: item, at: at, seq: q.seq})
+
+	// Waiters sleep until the previous head is ready; wake them if the new
+	// element is due earlier
+	if q.waiters > 0 && (err != nil || at.Before(head.at)) {
+		close(q.wake)
+		q.wake = make(chan struct{})
+	}
+}
+
+// PushAfter adds an element that becomes ready after the given delay
+func (q *DelayQueue[T]) PushAfter(item T, d time.Duration) {
+	q.Push(item, q.clock.Now().Add(d))
+}
+
+// NextReady returns the ready time of the earliest element
+func (q *DelayQueue[T]) NextReady() (time.Time, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	head, err := q.queue.Peek()
+	return head.at, err
+}
+
+// PopReady removes and returns the earliest element if its ready time has
+// passed. It returns ErrEmpty for an empty queue and ErrNotReady when no
+// element is ready yet.
+func (q *DelayQueue[T]) PopReady() (T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item, _, err := q.popReady()
+	return item, err
+}
+
+// PopWait removes and returns the earliest element, sleeping until its
+// ready time has passed. Elements pushed while waiting are taken into
+// account. It returns ctx.Err() if the context is done first.
+func (q *DelayQueue[T]) PopWait(ctx context.Context) (T, error) {
+	for {
+		q.mu.Lock()
+		item, wait, err := q.popReady()
+		if err == nil {
+			q.mu.Unlock()
+			return item, nil
+		}
+
+		var timer <-chan time.Time
+		if err == ErrNotReady {
+			timer = q.clock.After(wait)
+		}
+		wake := q.wake
+		q.waiters++
+		q.mu.Unlock()
+
+		select {
+		case <-timer:
+		case <-wake:
+		case <-ctx.Done():
+			q.mu.Lock()
+			q.waiters--
+			q.mu.Unlock()
+			return item, ctx.Err()
+		}
+
+		q.mu.Lock()
+		q.waiters--
+		q.mu.Unlock()
+	}
+}
+
+// popReady pops the earliest element if it is ready. Otherwise it reports
+// how long until it will be. The caller must hold q.mu.
+func (q *DelayQueue[T]) popReady() (T, time.Duration, error) {
+	var zero T
+	head, err := q.queue.Peek()
+	if err != nil {
+		return zero, 0, err
+	}
+
+	if wait := head.at.Sub(q.clock.Now()); wait > 0 {
+		return zero, wait, ErrNotReady
+	}
+	q.queue.Pop()
+	return head.value, 0, nil
+}
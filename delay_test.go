@@ -0,0 +1,230 @@
+package pqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced Clock for deterministic tests
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []fakeTimer
+	started chan struct{} // receives a value each time After is called
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		started: make(chan struct{}, 64),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+	} else {
+		c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), ch: ch})
+	}
+	c.started <- struct{}{}
+	return ch
+}
+
+// Advance moves the clock forward and fires every timer that is due
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, tm := range c.timers {
+		if tm.at.After(c.now) {
+			pending = append(pending, tm)
+		} else {
+			tm.ch <- c.now
+		}
+	}
+	c.timers = pending
+}
+
+// waitTimer blocks until a PopWait call has armed a timer
+func (c *fakeClock) waitTimer(t *testing.T) {
+	t.Helper()
+	select {
+	case <-c.started:
+	case <-time.After(time.Second):
+		t.Fatal("No timer was armed")
+	}
+}
+
+// TestDelayQueuePopReady tests that only elements whose time has passed pop
+func TestDelayQueuePopReady(t *testing.T) {
+	clock := newFakeClock()
+	q := NewDelayQueue[string](WithClock(clock))
+
+	if _, err := q.PopReady(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("PopReady on empty queue: got %v, want ErrEmpty", err)
+	}
+
+	q.PushAfter("c", 3*time.Second)
+	q.PushAfter("a", time.Second)
+	q.PushAfter("b", 2*time.Second)
+	q.PushAfter("a2", time.Second)
+
+	if _, err := q.PopReady(); !errors.Is(err, ErrNotReady) {
+		t.Errorf("PopReady before ready time: got %v, want ErrNotReady", err)
+	}
+	if at, err := q.NextReady(); err != nil || !at.Equal(clock.Now().Add(time.Second)) {
+		t.Errorf("NextReady = %v, %v", at, err)
+	}
+
+	clock.Advance(2 * time.Second)
+	for _, want := range []string{"a", "a2", "b"} {
+		if v, err := q.PopReady(); err != nil || v != want {
+			t.Errorf("PopReady = %q, %v, want %q, nil", v, err, want)
+		}
+	}
+	if _, err := q.PopReady(); !errors.Is(err, ErrNotReady) {
+		t.Errorf("Expected ErrNotReady, got %v", err)
+	}
+	if q.Size() != 1 {
+		t.Errorf("Expected size 1, got %d", q.Size())
+	}
+}
+
+// TestDelayQueuePopWait tests that PopWait sleeps until the head is ready
+func TestDelayQueuePopWait(t *testing.T) {
+	clock := newFakeClock()
+	q := NewDelayQueue[int](WithClock(clock))
+	q.PushAfter(1, 5*time.Second)
+
+	result := make(chan int)
+	go func() {
+		v, err := q.PopWait(context.Background())
+		if err != nil {
+			t.Errorf("PopWait: %v", err)
+		}
+		result <- v
+	}()
+
+	clock.waitTimer(t)
+	clock.Advance(4 * time.Second)
+	select {
+	case v := <-result:
+		t.Fatalf("PopWait returned %d before the ready time", v)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case v := <-result:
+		if v != 1 {
+			t.Errorf("PopWait = %d, want 1", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PopWait did not wake up at the ready time")
+	}
+}
+
+// TestDelayQueueEarlierPush tests that an earlier push shortens a wait
+func TestDelayQueueEarlierPush(t *testing.T) {
+	clock := newFakeClock()
+	q := NewDelayQueue[int](WithClock(clock))
+
+	result := make(chan int)
+	go func() {
+		v, err := q.PopWait(context.Background())
+		if err != nil {
+			t.Errorf("PopWait: %v", err)
+		}
+		result <- v
+	}()
+
+	// Wait for the goroutine to block on the empty queue
+	for {
+		q.mu.Lock()
+		waiting := q.waiters > 0
+		q.mu.Unlock()
+		if waiting {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	q.PushAfter(10, 10*time.Second)
+	clock.waitTimer(t)
+	q.PushAfter(1, time.Second)
+	clock.waitTimer(t)
+
+	clock.Advance(time.Second)
+	select {
+	case v := <-result:
+		if v != 1 {
+			t.Errorf("PopWait = %d, want 1", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PopWait did not pick up the earlier element")
+	}
+	if q.Size() != 1 {
+		t.Errorf("Expected size 1, got %d", q.Size())
+	}
+}
+
+// TestDelayQueuePopWaitCancel tests context cancellation of PopWait
+func TestDelayQueuePopWaitCancel(t *testing.T) {
+	clock := newFakeClock()
+	q := NewDelayQueue[int](WithClock(clock))
+	q.PushAfter(1, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, err := q.PopWait(ctx)
+		errs <- err
+	}()
+
+	clock.waitTimer(t)
+	cancel()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PopWait did not return after cancellation")
+	}
+	if q.Size() != 1 {
+		t.Errorf("Expected size 1, got %d", q.Size())
+	}
+}
+
+// TestDelayQueueSystemClock tests the default clock with a short real delay
+func TestDelayQueueSystemClock(t *testing.T) {
+	q := NewDelayQueue[int]()
+	start := time.Now()
+	q.PushAfter(1, 10*time.Millisecond)
+
+	v, err := q.PopWait(context.Background())
+	if err != nil || v != 1 {
+		t.Fatalf("PopWait = %d, %v, want 1, nil", v, err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("PopWait returned after %v, before the ready time", elapsed)
+	}
+}
@@ -0,0 +1,113 @@
+package pqueue
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SortPlan describes the strategy Sort would pick for the queue and the
+// measurements behind the choice
+type SortPlan struct {
+	Strategy      SortStrategy // strategy AutoStrategy resolves to
+	DataType      DataType     // inferred type of the elements
+	Size          int          // number of elements
+	Presortedness float64      // fraction of adjacent pairs already in order
+	IntRange      uint64       // largest minus smallest element, if HasIntRange
+	HasIntRange   bool         // elements are integers in their natural order
+	Rule          string       // the selection rule that picked Strategy
+	Profile       Profile      // sampled measures of the distribution
+}
+
+// SortReport describes a completed sort: its plan and the work it did
+type SortReport struct {
+	SortPlan
+	Comparisons int           // calls to less
+	Swaps       int           // exchanges of two elements
+	Elapsed     time.Duration // wall time of the sort
+}
+
+// Explain returns the plan Sort would follow without sorting. Measuring
+// presortedness takes a pass over the elements.
+func (pq *PQueue[T]) Explain() SortPlan {
+	return pq.view().plan()
+}
+
+// SortWithReport sorts the queue as Sort does and reports the plan
+// followed and the work done. Key-based strategies sort without calling
+// less, and merge-based ones move elements without exchanging them, so
+// they report no comparisons or swaps respectively.
+func (pq *PQueue[T]) SortWithReport() SortReport {
+	report := SortReport{SortPlan: pq.Explain()}
+
+	// Node backends are rebuilt here, after less is restored, so the
+	// rebuilt heap does not keep the counting wrapper
+	if pq.flatten() {
+		defer pq.useHeap(pq.heapKind)
+	}
+
+	// Parallel sorts call less from several goroutines
+	var comparisons atomic.Int64
+	less := pq.less
+	pq.less = func(a, b T) bool {
+		comparisons.Add(1)
+		return less(a, b)
+	}
+	pq.swaps = 0
+
+	start := time.Now()
+	pq.SortWithStrategy(report.Strategy)
+	report.Elapsed = time.Since(start)
+
+	pq.less = less
+	report.Comparisons = int(comparisons.Load())
+	report.Swaps = pq.swaps
+	return report
+}
+
+// plan measures the queue data and selects a strategy for it
+func (pq *PQueue[T]) plan() SortPlan {
+	strategy, rule := pq.selectStrategy()
+	if pq.refs != nil {
+		strategy = trackedStrategy(strategy)
+	}
+	plan := SortPlan{
+		Strategy:      strategy,
+		DataType:      pq.dataType,
+		Size:          pq.size,
+		Presortedness: pq.presortedness(),
+		Rule:          rule,
+		Profile:       pq.profile(pq.opts.selection().SampleSize),
+	}
+	if s, ok := pq.integers(); ok && pq.size > 0 {
+		lo, hi := s.keyRange()
+		plan.IntRange, plan.HasIntRange = hi-lo, true
+	}
+	if a := pq.opts.selector; a != nil && pq.size > pq.opts.selection().SmallSize {
+		if s, ok := a.best(pq.shape(), pq.candidates(strategy)); ok {
+			plan.Strategy, plan.Rule = s, "fastest measured for this shape"
+		}
+	}
+	return plan
+}
+
+// presortedness returns the fraction of adjacent pairs of data that are in
+// order, 1 for fewer than two elements
+func (pq *PQueue[T]) presortedness() float64 {
+	if pq.size < 2 {
+		return 1
+	}
+	ordered := 0
+	for i := 1; i < pq.size; i++ {
+		if !pq.less(pq.data[i], pq.data[i-1]) {
+			ordered++
+		}
+	}
+	return float64(ordered) / float64(pq.size-1)
+}
+
+// swap exchanges two elements of data, counting the exchange for
+// SortWithReport
+func (pq *PQueue[T]) swap(i, j int) {
+	pq.data[i], pq.data[j] = pq.data[j], pq.data[i]
+	pq.swaps++
+}
@@ -0,0 +1,175 @@
+package pqueue
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+)
+
+// TestExplain tests the plan reported for inputs that trigger each rule
+func TestExplain(t *testing.T) {
+	ascending := make([]int, 1000)
+	for i := range ascending {
+		ascending[i] = i
+	}
+	smallRange := make([]int, 1000)
+	for i := range smallRange {
+		smallRange[i] = rand.Intn(50) - 25
+	}
+	type point struct{ x, y int }
+	points := make([]point, 1000)
+	for i := range points {
+		points[i] = point{rand.Intn(100), rand.Intn(100)}
+	}
+	byX := func(a, b point) bool { return a.x < b.x }
+
+	tests := []struct {
+		name     string
+		pq       interface{ Explain() SortPlan }
+		strategy SortStrategy
+		rule     string
+	}{
+		{"small", NewInts([]int{3, 1, 2}), InsertionStrategy, "small input"},
+		{"sorted", New(ascending, func(a, b int) bool { return a < b }), InsertionStrategy, "nearly sorted"},
+		{"small range", NewInts(smallRange), CountingStrategy, "integers with a small range"},
+		{"integers", NewInts(generateRandomInts(1000)), RadixStrategy, "integers"},
+		{"floats", NewFloats(generateRandomFloats(1000)), RadixStrategy, "floats"},
+		{"prefixed", NewStrings(generateLogKeys(1000)), MSDRadixStrategy, "sequences with a long common prefix"},
+		{"parallel", New(points, byX, WithWorkers(4), WithParallelThreshold(500)), ParallelMergeStrategy, "large input with several workers"},
+		{"slices", New([][]int{{3}, {1}, {2}, {5}, {4}, {9}, {8}, {7}, {6}, {0}, {11}, {10}, {13}, {12}, {15}, {14}, {16}}, func(a, b []int) bool { return a[0] < b[0] }), MergeStrategy, "slice or array elements"},
+		{"default", New(points, byX, WithWorkers(1)), PdqStrategy, "default"},
+	}
+	for _, tt := range tests {
+		plan := tt.pq.Explain()
+		if plan.Strategy != tt.strategy || plan.Rule != tt.rule {
+			t.Errorf("%s: got %v by %q, want %v by %q", tt.name, plan.Strategy, plan.Rule, tt.strategy, tt.rule)
+		}
+	}
+
+	plan := NewInts(smallRange).Explain()
+	if !plan.HasIntRange || plan.IntRange > 49 || plan.Size != 1000 || plan.DataType != IntegerType {
+		t.Errorf("Small range plan: %+v", plan)
+	}
+	if plan := New(points, byX).Explain(); plan.HasIntRange || plan.DataType != StructType {
+		t.Errorf("Struct plan: %+v", plan)
+	}
+}
+
+// TestExplainPresortedness tests the measure at its extremes and that
+// Explain leaves every backend untouched
+func TestExplainPresortedness(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	for _, hk := range allHeapKinds {
+		pq := New(generateRandomInts(500), less, WithHeapKind(hk.kind))
+		before := pq.ToSlice()
+		if p := pq.Explain().Presortedness; p <= 0 || p >= 1 {
+			t.Errorf("%s: presortedness %v of a heap", hk.name, p)
+		}
+		if !slices.Equal(pq.ToSlice(), before) {
+			t.Errorf("%s: Explain modified the queue", hk.name)
+		}
+	}
+
+	descending := make([]int, 100)
+	for i := range descending {
+		descending[i] = -i
+	}
+	pq := sliceQueue(descending, less)
+	if p := pq.presortedness(); p != 0 {
+		t.Errorf("Descending presortedness %v, want 0", p)
+	}
+	pq.pdqsort()
+	if p := pq.presortedness(); p != 1 {
+		t.Errorf("Sorted presortedness %v, want 1", p)
+	}
+}
+
+// TestSortWithReport tests the counts reported for comparison, key-based
+// and parallel sorts, with and without handles
+func TestSortWithReport(t *testing.T) {
+	type record struct {
+		key  int
+		name string
+	}
+	records := make([]record, 5000)
+	for i := range records {
+		records[i] = record{rand.Intn(1000), "r"}
+	}
+	less := func(a, b record) bool { return a.key < b.key }
+	isSorted := func(s []record) bool {
+		return sort.SliceIsSorted(s, func(i, j int) bool { return less(s[i], s[j]) })
+	}
+
+	pq := New(records, less, WithWorkers(1))
+	report := pq.SortWithReport()
+	if report.Strategy != PdqStrategy || report.Comparisons == 0 || report.Swaps == 0 || report.Elapsed <= 0 {
+		t.Errorf("Pdq report: %+v", report)
+	}
+	if !isSorted(pq.ToSlice()) {
+		t.Error("Pdq: not sorted")
+	}
+
+	// The counting wrapper is removed afterwards
+	again := pq.SortWithReport()
+	if again.Comparisons != pq.size-1 || again.Presortedness != 1 {
+		t.Errorf("Sorted input report: %+v", again)
+	}
+
+	// Sorting with handles exchanges indices, which are counted too
+	tracked := New(records[:1000], less, WithWorkers(1))
+	h := tracked.PushHandle(record{-1, "h"})
+	if report := tracked.SortWithReport(); report.Swaps == 0 {
+		t.Errorf("Tracked report: %+v", report)
+	}
+	if v, err := tracked.Value(h); err != nil || v.name != "h" {
+		t.Errorf("Value = %v, %v", v, err)
+	}
+
+	// Tracked integer queues report the pdqsort that replaces radix sort
+	trackedInts := NewInts(generateRandomInts(5000), WithWorkers(1))
+	trackedInts.PushHandle(-1)
+	if plan := trackedInts.Explain(); plan.Strategy != PdqStrategy {
+		t.Errorf("Tracked integers plan %v by %q, want Pdq", plan.Strategy, plan.Rule)
+	}
+	if report := trackedInts.SortWithReport(); report.Strategy != PdqStrategy || report.Comparisons == 0 {
+		t.Errorf("Tracked integers report: %+v", report)
+	}
+
+	// Node backends are rebuilt without the counting wrapper
+	for _, kind := range []HeapKind{PairingHeap, FibonacciHeap} {
+		q := New(records[:1000], less, WithHeapKind(kind), WithWorkers(1))
+		if report := q.SortWithReport(); report.Comparisons == 0 || !isSorted(q.ToSlice()) {
+			t.Errorf("Kind %v: report %+v", kind, report)
+		}
+		var heapLess func(a, b record) bool
+		switch h := q.nodes.(type) {
+		case *pairingHeap[record]:
+			heapLess = h.less
+		case *fibonacciHeap[record]:
+			heapLess = h.less
+		}
+		if heapLess == nil || !sameLess(heapLess, less) {
+			t.Errorf("Kind %v: node heap kept the counting wrapper", kind)
+		}
+		q.Push(record{-1, "min"})
+		if v, _ := q.Pop(); v.key != -1 {
+			t.Errorf("Kind %v: Pop = %v after the report", kind, v)
+		}
+		assertHeap(t, q)
+	}
+
+	ints := NewInts(generateRandomInts(5000))
+	if report := ints.SortWithReport(); report.Strategy != RadixStrategy || report.Comparisons != 0 {
+		t.Errorf("Radix report: %+v", report)
+	}
+
+	parallel := New(records, less, WithWorkers(4), WithParallelThreshold(1000))
+	report = parallel.SortWithReport()
+	if report.Strategy != ParallelMergeStrategy || report.Comparisons == 0 {
+		t.Errorf("Parallel report: %+v", report)
+	}
+	if !isSorted(parallel.ToSlice()) {
+		t.Error("Parallel: not sorted")
+	}
+}
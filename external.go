@@ -0,0 +1,302 @@
+package pqueue
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"iter"
+	"os"
+	"unsafe"
+)
+
+// defaultMemoryLimit is the number of bytes ExternalSorter buffers before
+// spilling unless WithMemoryLimit says otherwise
+const defaultMemoryLimit = 64 << 20
+
+// externalMaxFanIn is the number of runs merged at once. Sorts with more
+// runs merge them in intermediate passes to bound open files.
+const externalMaxFanIn = 128
+
+// Codec writes elements to and reads them from the files ExternalSorter
+// spills. Decode must return io.EOF, and nothing else, when the reader
+// holds no further element.
+type Codec[T any] interface {
+	Encode(w *bufio.Writer, v T) error
+	Decode(r *bufio.Reader) (T, error)
+}
+
+// BinaryCodec encodes fixed-size values, such as int64, float64 or structs
+// and arrays of them, with encoding/binary in little-endian byte order.
+// Types whose size is not fixed, including int and string, fail to encode.
+type BinaryCodec[T any] struct{}
+
+func (BinaryCodec[T]) Encode(w *bufio.Writer, v T) error {
+	return binary.Write(w, binary.LittleEndian, v)
+}
+
+func (BinaryCodec[T]) Decode(r *bufio.Reader) (T, error) {
+	var v T
+	err := binary.Read(r, binary.LittleEndian, &v)
+	return v, err
+}
+
+// StringCodec encodes strings prefixed with their length as a uvarint
+type StringCodec struct{}
+
+func (StringCodec) Encode(w *bufio.Writer, v string) error {
+	var prefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(prefix[:], uint64(len(v)))
+	if _, err := w.Write(prefix[:n]); err != nil {
+		return err
+	}
+	_, err := w.WriteString(v)
+	return err
+}
+
+func (StringCodec) Decode(r *bufio.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return string(b), nil
+}
+
+// ExternalSorter sorts more elements than fit in memory. Added elements
+// are buffered up to the memory limit, sorted with AutoStrategy and
+// spilled as runs to temporary files; Sort then merges the runs with
+// MergeSorted. It is not safe for concurrent use.
+type ExternalSorter[T any] struct {
+	less  func(T, T) bool
+	codec Codec[T]
+	opts  options
+	buf   []T
+	bytes int      // estimated memory held by buf
+	runs  []string // paths of the spilled runs, in spill order
+}
+
+// NewExternalSorter creates an ExternalSorter ordering elements by less
+// and spilling them with codec. WithMemoryLimit and WithTempDir configure
+// spilling; the sorting options of New apply to each in-memory sort.
+func NewExternalSorter[T any](less func(T, T) bool, codec Codec[T], opts ...Option) *ExternalSorter[T] {
+	return &ExternalSorter[T]{
+		less:  less,
+		codec: codec,
+		opts:  newOptions(opts),
+	}
+}
+
+// Add buffers v, spilling the buffer as a sorted run once it reaches the
+// memory limit
+func (s *ExternalSorter[T]) Add(v T) error {
+	s.buf = append(s.buf, v)
+	s.bytes += elementSize(v)
+	if s.bytes >= s.memoryLimit() {
+		return s.spill()
+	}
+	return nil
+}
+
+// AddAll adds every element of seq, stopping at the first error
+func (s *ExternalSorter[T]) AddAll(seq iter.Seq[T]) error {
+	for v := range seq {
+		if err := s.Add(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Runs returns the number of runs spilled to disk so far
+func (s *ExternalSorter[T]) Runs() int {
+	return len(s.runs)
+}
+
+// Sort writes every added element to w in order, encoded with the codec,
+// and removes the runs from disk. Elements that never left memory are
+// written without touching disk. The sorter is empty afterwards, even on
+// error, and can be reused.
+func (s *ExternalSorter[T]) Sort(w io.Writer) (err error) {
+	defer func() {
+		if cerr := s.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	out := bufio.NewWriter(w)
+
+	if len(s.runs) == 0 {
+		s.sortBuffer()
+		for _, v := range s.buf {
+			if err := s.codec.Encode(out, v); err != nil {
+				return err
+			}
+		}
+		return out.Flush()
+	}
+
+	if len(s.buf) > 0 {
+		if err := s.spill(); err != nil {
+			return err
+		}
+	}
+	for len(s.runs) > externalMaxFanIn {
+		if err := s.mergePass(); err != nil {
+			return err
+		}
+	}
+	if err := s.mergeRuns(s.runs, out); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// Close discards buffered elements and removes any runs from disk
+func (s *ExternalSorter[T]) Close() error {
+	var errs []error
+	for _, path := range s.runs {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	clear(s.buf)
+	s.buf, s.bytes, s.runs = s.buf[:0], 0, nil
+	return errors.Join(errs...)
+}
+
+// memoryLimit returns the number of bytes to buffer before spilling
+func (s *ExternalSorter[T]) memoryLimit() int {
+	if s.opts.memoryLimit > 0 {
+		return s.opts.memoryLimit
+	}
+	return defaultMemoryLimit
+}
+
+// sortBuffer sorts the buffered elements in place
+func (s *ExternalSorter[T]) sortBuffer() {
+	pq := sliceQueue(s.buf, s.less)
+	pq.opts = s.opts
+	pq.Sort()
+}
+
+// spill sorts the buffer and writes it to a new run file
+func (s *ExternalSorter[T]) spill() error {
+	s.sortBuffer()
+	f, err := os.CreateTemp(s.opts.tempDir, "pqueue-run-*")
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, f.Name()) // recorded first so Close removes it on failure
+
+	w := bufio.NewWriter(f)
+	for _, v := range s.buf {
+		if err = s.codec.Encode(w, v); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	clear(s.buf)
+	s.buf, s.bytes = s.buf[:0], 0
+	return nil
+}
+
+// mergePass merges the runs in groups of externalMaxFanIn into fewer,
+// longer runs, keeping their order
+func (s *ExternalSorter[T]) mergePass() error {
+	var merged []string
+	for len(s.runs) > 0 {
+		group := s.runs[:min(len(s.runs), externalMaxFanIn)]
+		f, err := os.CreateTemp(s.opts.tempDir, "pqueue-run-*")
+		if err != nil {
+			s.runs = append(s.runs, merged...)
+			return err
+		}
+		merged = append(merged, f.Name())
+
+		w := bufio.NewWriter(f)
+		err = s.mergeRuns(group, w)
+		if err == nil {
+			err = w.Flush()
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			s.runs = append(s.runs, merged...)
+			return err
+		}
+
+		for _, path := range group {
+			os.Remove(path)
+		}
+		s.runs = s.runs[len(group):]
+	}
+	s.runs = merged
+	return nil
+}
+
+// mergeRuns merges the run files at paths into w with MergeSorted, so
+// equal elements are taken from earlier runs first
+func (s *ExternalSorter[T]) mergeRuns(paths []string, w *bufio.Writer) error {
+	var readErr error
+	runs := make([]iter.Seq[T], len(paths))
+	for i, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		r := bufio.NewReader(f)
+		runs[i] = func(yield func(T) bool) {
+			for {
+				v, err := s.codec.Decode(r)
+				if err != nil {
+					if err != io.EOF && readErr == nil {
+						readErr = err
+					}
+					return
+				}
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+
+	for v := range MergeSorted(s.less, runs...) {
+		if err := s.codec.Encode(w, v); err != nil {
+			return err
+		}
+	}
+	return readErr
+}
+
+// elementSize estimates the memory held by v: its own size plus the bytes
+// of a string or byte slice
+func elementSize[T any](v T) int {
+	n := int(unsafe.Sizeof(v))
+	switch x := any(v).(type) {
+	case string:
+		n += len(x)
+	case []byte:
+		n += cap(x)
+	}
+	return n
+}
@@ -0,0 +1,150 @@
+package pqueue
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"slices"
+	"testing"
+)
+
+// decodeAll reads every element from b with codec
+func decodeAll[T any](t *testing.T, b []byte, codec Codec[T]) []T {
+	t.Helper()
+	r := bufio.NewReader(bytes.NewReader(b))
+	var out []T
+	for {
+		v, err := codec.Decode(r)
+		if err != nil {
+			if err != io.EOF {
+				t.Fatalf("Decode: %v", err)
+			}
+			return out
+		}
+		out = append(out, v)
+	}
+}
+
+// checkTempDirEmpty fails if runs were left behind in dir
+func checkTempDirEmpty(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("%d run files left in the temp dir", len(entries))
+	}
+}
+
+// TestExternalSorter tests spilling, the k-way merge and intermediate
+// merge passes
+func TestExternalSorter(t *testing.T) {
+	less := func(a, b int64) bool { return a < b }
+	for _, limit := range []int{1 << 30, 8000, 800} { // in memory, ~13 runs, ~1250 runs
+		dir := t.TempDir()
+		data := make([]int64, 100000)
+		for i := range data {
+			data[i] = rand.Int63n(1000) - 500
+		}
+
+		s := NewExternalSorter(less, BinaryCodec[int64]{}, WithMemoryLimit(limit), WithTempDir(dir))
+		if err := s.AddAll(slices.Values(data)); err != nil {
+			t.Fatalf("AddAll: %v", err)
+		}
+		runs := s.Runs()
+		if limit < 1<<30 && runs < 2 {
+			t.Errorf("Limit %d: expected spilled runs, got %d", limit, runs)
+		}
+
+		var out bytes.Buffer
+		if err := s.Sort(&out); err != nil {
+			t.Fatalf("Sort: %v", err)
+		}
+		slices.Sort(data)
+		if got := decodeAll(t, out.Bytes(), BinaryCodec[int64]{}); !slices.Equal(got, data) {
+			t.Errorf("Limit %d with %d runs: output not sorted", limit, runs)
+		}
+		checkTempDirEmpty(t, dir)
+	}
+}
+
+// TestExternalSorterStrings tests StringCodec, including empty strings
+func TestExternalSorterStrings(t *testing.T) {
+	dir := t.TempDir()
+	data := generateLogKeys(20000)
+	s := NewExternalSorter(func(a, b string) bool { return a < b }, StringCodec{}, WithMemoryLimit(64<<10), WithTempDir(dir))
+	for _, v := range data {
+		if err := s.Add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := s.Sort(&out); err != nil {
+		t.Fatalf("Sort: %v", err)
+	}
+	slices.Sort(data)
+	if got := decodeAll(t, out.Bytes(), StringCodec{}); !slices.Equal(got, data) {
+		t.Error("Output not sorted")
+	}
+	checkTempDirEmpty(t, dir)
+
+	// The sorter is reusable after Sort
+	s.Add("b")
+	s.Add("a")
+	out.Reset()
+	if err := s.Sort(&out); err != nil {
+		t.Fatal(err)
+	}
+	if got := decodeAll(t, out.Bytes(), StringCodec{}); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("Reused sorter wrote %v", got)
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+// TestExternalSorterErrors tests that errors are reported and runs are
+// removed on every path
+func TestExternalSorterErrors(t *testing.T) {
+	dir := t.TempDir()
+	s := NewExternalSorter(func(a, b int64) bool { return a < b }, BinaryCodec[int64]{}, WithMemoryLimit(800), WithTempDir(dir))
+	for i := 0; i < 10000; i++ {
+		s.Add(rand.Int63())
+	}
+	if err := s.Sort(failingWriter{}); err == nil || err.Error() != "disk full" {
+		t.Errorf("Expected the writer error, got %v", err)
+	}
+	checkTempDirEmpty(t, dir)
+
+	// Close discards an abandoned sort
+	for i := 0; i < 1000; i++ {
+		s.Add(rand.Int63())
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	checkTempDirEmpty(t, dir)
+
+	// Types without a fixed size cannot be encoded
+	ints := NewExternalSorter(func(a, b int) bool { return a < b }, BinaryCodec[int]{}, WithMemoryLimit(8), WithTempDir(dir))
+	if err := ints.Add(1); err == nil {
+		t.Error("Expected an encoding error for int")
+	}
+	ints.Close()
+	checkTempDirEmpty(t, dir)
+
+	// A missing temp dir fails the spill
+	missing := NewExternalSorter(func(a, b int64) bool { return a < b }, BinaryCodec[int64]{}, WithMemoryLimit(8), WithTempDir(dir+"/missing"))
+	if err := missing.Add(1); err == nil {
+		t.Error("Expected an error creating the run file")
+	}
+}
@@ -0,0 +1,209 @@
+package pqueue
+
+// fibonacciHeap is a Fibonacci heap: O(1) insert and amortized O(log n)
+// extractMin. Roots and siblings are kept in circular doubly linked lists.
+type fibonacciHeap[T any] struct {
+	minNode *heapNode[T]
+	less    func(T, T) bool
+	roots   []*heapNode[T] // scratch space reused by consolidate
+	degrees []*heapNode[T] // scratch space reused by consolidate
+}
+
+func (h *fibonacciHeap[T]) insert(n *heapNode[T]) {
+	n.parent, n.child = nil, nil
+	n.degree, n.marked = 0, false
+	n.left, n.right = n, n
+	h.addRoot(n)
+}
+
+func (h *fibonacciHeap[T]) min() *heapNode[T] {
+	return h.minNode
+}
+
+func (h *fibonacciHeap[T]) extractMin() *heapNode[T] {
+	z := h.minNode
+
+	// Promote the children of the minimum to roots
+	if child := z.child; child != nil {
+		for n := child; ; {
+			next := n.right
+			n.parent = nil
+			n.marked = false
+			splice(z, n)
+			n = next
+			if n == child {
+				break
+			}
+		}
+		z.child = nil
+		z.degree = 0
+	}
+
+	if z.right == z {
+		h.minNode = nil
+	} else {
+		h.minNode = z.right
+		unlink(z)
+		h.consolidate()
+	}
+	z.left, z.right = nil, nil
+	return z
+}
+
+func (h *fibonacciHeap[T]) decreaseKey(n *heapNode[T]) {
+	if p := n.parent; p != nil && h.less(n.value, p.value) {
+		h.cut(n, p)
+		h.cascadingCut(p)
+	}
+	if h.less(n.value, h.minNode.value) {
+		h.minNode = n
+	}
+}
+
+func (h *fibonacciHeap[T]) remove(n *heapNode[T]) {
+	if p := n.parent; p != nil {
+		h.cut(n, p)
+		h.cascadingCut(p)
+	}
+	// Treat n as the minimum regardless of its value and extract it
+	h.minNode = n
+	h.extractMin()
+}
+
+func (h *fibonacciHeap[T]) each(fn func(n *heapNode[T]) bool) {
+	walkNodes(h.minNode, fn)
+}
+
+func (h *fibonacciHeap[T]) meld(other nodeHeap[T]) {
+	o := other.(*fibonacciHeap[T])
+	m := o.minNode
+	o.minNode = nil
+	if m == nil {
+		return
+	}
+	if h.minNode == nil {
+		h.minNode = m
+		return
+	}
+
+	// Join the two circular root lists
+	a, b := h.minNode, m
+	aLast, bLast := a.left, b.left
+	aLast.right, b.left = b, aLast
+	bLast.right, a.left = a, bLast
+	if h.less(m.value, h.minNode.value) {
+		h.minNode = m
+	}
+}
+
+// addRoot adds a detached single node to the root list
+func (h *fibonacciHeap[T]) addRoot(n *heapNode[T]) {
+	if h.minNode == nil {
+		n.left, n.right = n, n
+		h.minNode = n
+		return
+	}
+	splice(h.minNode, n)
+	if h.less(n.value, h.minNode.value) {
+		h.minNode = n
+	}
+}
+
+// consolidate links roots of equal degree until every degree is unique
+func (h *fibonacciHeap[T]) consolidate() {
+	roots := h.roots[:0]
+	for n := h.minNode; ; {
+		roots = append(roots, n)
+		n = n.right
+		if n == h.minNode {
+			break
+		}
+	}
+
+	degrees := h.degrees[:0]
+	for _, x := range roots {
+		d := x.degree
+		for d < len(degrees) && degrees[d] != nil {
+			y := degrees[d]
+			if h.less(y.value, x.value) {
+				x, y = y, x
+			}
+			h.adopt(x, y)
+			degrees[d] = nil
+			d++
+		}
+		for len(degrees) <= d {
+			degrees = append(degrees, nil)
+		}
+		degrees[d] = x
+	}
+
+	h.minNode = nil
+	for _, n := range degrees {
+		if n != nil && (h.minNode == nil || h.less(n.value, h.minNode.value)) {
+			h.minNode = n
+		}
+	}
+
+	clear(roots)
+	clear(degrees)
+	h.roots, h.degrees = roots[:0], degrees[:0]
+}
+
+// adopt removes root y from the root list and makes it a child of x
+func (h *fibonacciHeap[T]) adopt(x, y *heapNode[T]) {
+	unlink(y)
+	y.left, y.right = y, y
+	y.parent = x
+	y.marked = false
+	if x.child == nil {
+		x.child = y
+	} else {
+		splice(x.child, y)
+	}
+	x.degree++
+}
+
+// cut moves n from the child list of p to the root list
+func (h *fibonacciHeap[T]) cut(n, p *heapNode[T]) {
+	if n.right == n {
+		p.child = nil
+	} else {
+		if p.child == n {
+			p.child = n.right
+		}
+		unlink(n)
+	}
+	p.degree--
+
+	n.parent = nil
+	n.marked = false
+	splice(h.minNode, n)
+}
+
+// cascadingCut cuts marked ancestors of p, marking the first unmarked one
+func (h *fibonacciHeap[T]) cascadingCut(p *heapNode[T]) {
+	for p.parent != nil {
+		if !p.marked {
+			p.marked = true
+			return
+		}
+		parent := p.parent
+		h.cut(p, parent)
+		p = parent
+	}
+}
+
+// splice inserts n into the circular list containing at, just before at
+func splice[T any](at, n *heapNode[T]) {
+	n.right = at
+	n.left = at.left
+	at.left.right = n
+	at.left = n
+}
+
+// unlink removes n from its circular list, leaving n's own links untouched
+func unlink[T any](n *heapNode[T]) {
+	n.left.right = n.right
+	n.right.left = n.left
+}
@@ -0,0 +1,163 @@
+package pqueue
+
+// Handle refers to an element added with PushHandle. It stays valid across
+// heap reshuffles, growth, sorting and backend switches until the element
+// is popped or removed. A handle must only be used with the queue that
+// issued it, or the queue its element was merged into.
+type Handle[T any] struct {
+	index int          // position in data for the array backends, -1 otherwise
+	node  *heapNode[T] // node for the pairing and Fibonacci backends
+}
+
+// Valid reports whether the handle still refers to an element in its queue
+func (h *Handle[T]) Valid() bool {
+	return h != nil && (h.index >= 0 || h.node != nil)
+}
+
+// PushHandle adds an element to the queue and returns a handle to it
+func (pq *PQueue[T]) PushHandle(item T) *Handle[T] {
+	h := &Handle[T]{index: -1}
+	if pq.nodes == nil && pq.refs == nil {
+		pq.refs = make([]*Handle[T], len(pq.data))
+	}
+	pq.push(item, h)
+	return h
+}
+
+// Value returns the element referred to by h
+func (pq *PQueue[T]) Value(h *Handle[T]) (T, error) {
+	var zero T
+	if !pq.owns(h) {
+		return zero, ErrInvalidHandle
+	}
+	if h.node != nil {
+		return h.node.value, nil
+	}
+	return pq.data[h.index], nil
+}
+
+// Update replaces the element referred to by h with item and restores the
+// heap order in O(log n)
+func (pq *PQueue[T]) Update(h *Handle[T], item T) error {
+	if !pq.owns(h) {
+		return ErrInvalidHandle
+	}
+	pq.mods++
+	pq.record(opUpdate)
+
+	if n := h.node; n != nil {
+		old := n.value
+		n.value = item
+		if !pq.less(old, item) {
+			pq.nodes.decreaseKey(n)
+		} else {
+			pq.nodes.remove(n)
+			pq.nodes.insert(n)
+		}
+		return nil
+	}
+
+	pq.data[h.index] = item
+	pq.fix(h.index)
+	return nil
+}
+
+// Fix restores the heap order after the element referred to by h has been
+// modified in place, for example through a pointer, in O(log n)
+func (pq *PQueue[T]) Fix(h *Handle[T]) error {
+	if !pq.owns(h) {
+		return ErrInvalidHandle
+	}
+	pq.mods++
+	pq.record(opUpdate)
+
+	if n := h.node; n != nil {
+		pq.nodes.remove(n)
+		pq.nodes.insert(n)
+		return nil
+	}
+
+	pq.fix(h.index)
+	return nil
+}
+
+// Remove removes and returns the element referred to by h in O(log n).
+// The handle is invalid afterwards.
+func (pq *PQueue[T]) Remove(h *Handle[T]) (T, error) {
+	var zero T
+	if !pq.owns(h) {
+		return zero, ErrInvalidHandle
+	}
+	pq.mods++
+	pq.record(opPop)
+
+	if n := h.node; n != nil {
+		pq.nodes.remove(n)
+		pq.size--
+		return releaseNode(n), nil
+	}
+	return pq.removeAt(h.index), nil
+}
+
+// owns reports whether h currently refers to an element of this queue
+func (pq *PQueue[T]) owns(h *Handle[T]) bool {
+	if h == nil {
+		return false
+	}
+	if h.node != nil {
+		return pq.nodes != nil && h.node.handle == h
+	}
+	return h.index >= 0 && h.index < pq.size && pq.refs != nil && pq.refs[h.index] == h
+}
+
+// sortTracked sorts data with the given strategy while keeping refs aligned,
+// so handles follow their elements. It sorts a permutation of indices, which
+// key-based strategies cannot look through, so those use pdqsort instead.
+func (pq *PQueue[T]) sortTracked(strategy SortStrategy) {
+	strategy = trackedStrategy(strategy)
+	pq.permuteTracked(func(sorter *PQueue[int]) {
+		sorter.SortWithStrategy(strategy)
+	})
+}
+
+// trackedStrategy returns the strategy sortTracked sorts with when asked
+// for strategy
+func trackedStrategy(strategy SortStrategy) SortStrategy {
+	if strategy == RadixStrategy || strategy == CountingStrategy || strategy == MSDRadixStrategy {
+		return PdqStrategy
+	}
+	return strategy
+}
+
+// permuteTracked runs an in-place algorithm over a permutation of indices
+// ordered by the elements they refer to, then applies the permutation to
+// data and refs so handles follow their elements
+func (pq *PQueue[T]) permuteTracked(apply func(sorter *PQueue[int])) {
+	perm := make([]int, pq.size)
+	for i := range perm {
+		perm[i] = i
+	}
+
+	data := pq.data
+	sorter := &PQueue[int]{
+		data:     perm,
+		less:     func(a, b int) bool { return pq.less(data[a], data[b]) },
+		size:     len(perm),
+		dataType: GenericType,
+		arity:    2,
+		opts:     pq.opts,
+	}
+	apply(sorter)
+	pq.swaps += sorter.swaps
+
+	sorted := make([]T, len(pq.data))
+	refs := make([]*Handle[T], len(pq.refs))
+	for i, j := range perm {
+		sorted[i] = data[j]
+		refs[i] = pq.refs[j]
+		if refs[i] != nil {
+			refs[i].index = i
+		}
+	}
+	pq.data, pq.refs = sorted, refs
+}
@@ -0,0 +1,201 @@
+package pqueue
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// TestHandleOperations tests Update and Remove against a reference model
+func TestHandleOperations(t *testing.T) {
+	for _, hk := range allHeapKinds {
+		t.Run(hk.name, func(t *testing.T) {
+			pq := NewInts([]int{50, 40, 30}, WithHeapKind(hk.kind))
+			live := map[*Handle[int]]int{}
+
+			for i := 0; i < 3000; i++ {
+				switch op := rand.Intn(10); {
+				case op < 4 || len(live) == 0:
+					v := rand.Intn(1000)
+					live[pq.PushHandle(v)] = v
+				case op < 7:
+					h := anyHandle(live)
+					v := rand.Intn(1000)
+					if err := pq.Update(h, v); err != nil {
+						t.Fatalf("Update: %v", err)
+					}
+					live[h] = v
+				case op < 9:
+					h := anyHandle(live)
+					got, err := pq.Remove(h)
+					if err != nil {
+						t.Fatalf("Remove: %v", err)
+					}
+					if got != live[h] {
+						t.Fatalf("Remove = %d, want %d", got, live[h])
+					}
+					if h.Valid() {
+						t.Fatal("Expected handle to be invalid after Remove")
+					}
+					delete(live, h)
+				default:
+					pq.Sort()
+				}
+			}
+
+			assertHeap(t, pq)
+			for h, want := range live {
+				got, err := pq.Value(h)
+				if err != nil || got != want {
+					t.Fatalf("Value = %d, %v, want %d", got, err, want)
+				}
+			}
+
+			expected := []int{30, 40, 50}
+			for _, v := range live {
+				expected = append(expected, v)
+			}
+			sort.Ints(expected)
+			for i, want := range expected {
+				got, err := pq.Pop()
+				if err != nil || got != want {
+					t.Fatalf("Pop %d = %d, %v, want %d", i, got, err, want)
+				}
+			}
+			for h := range live {
+				if h.Valid() {
+					t.Fatal("Expected handle to be invalid after its element was popped")
+				}
+			}
+		})
+	}
+}
+
+// TestHandleStale tests that stale handles are rejected
+func TestHandleStale(t *testing.T) {
+	for _, hk := range allHeapKinds {
+		t.Run(hk.name, func(t *testing.T) {
+			pq := NewInts(nil, WithHeapKind(hk.kind))
+			h := pq.PushHandle(1)
+			pq.PushHandle(2)
+
+			if v, _ := pq.Pop(); v != 1 {
+				t.Fatalf("Expected 1, got %d", v)
+			}
+			if err := pq.Update(h, 0); !errors.Is(err, ErrInvalidHandle) {
+				t.Errorf("Update on popped handle: got %v, want ErrInvalidHandle", err)
+			}
+			if err := pq.Fix(h); !errors.Is(err, ErrInvalidHandle) {
+				t.Errorf("Fix on popped handle: got %v, want ErrInvalidHandle", err)
+			}
+			if _, err := pq.Remove(h); !errors.Is(err, ErrInvalidHandle) {
+				t.Errorf("Remove on popped handle: got %v, want ErrInvalidHandle", err)
+			}
+			if _, err := pq.Remove(nil); !errors.Is(err, ErrInvalidHandle) {
+				t.Errorf("Remove(nil): got %v, want ErrInvalidHandle", err)
+			}
+		})
+	}
+}
+
+// TestHandleFix tests Fix after modifying an element through a pointer
+func TestHandleFix(t *testing.T) {
+	type task struct {
+		name     string
+		priority int
+	}
+
+	for _, hk := range allHeapKinds {
+		t.Run(hk.name, func(t *testing.T) {
+			pq := New(nil, func(a, b *task) bool { return a.priority < b.priority }, WithHeapKind(hk.kind))
+			tasks := []*task{{"a", 5}, {"b", 3}, {"c", 8}, {"d", 1}}
+			handles := make([]*Handle[*task], len(tasks))
+			for i, tk := range tasks {
+				handles[i] = pq.PushHandle(tk)
+			}
+
+			tasks[2].priority = 0 // c moves to the front
+			tasks[3].priority = 9 // d moves to the back
+			for _, i := range []int{2, 3} {
+				if err := pq.Fix(handles[i]); err != nil {
+					t.Fatalf("Fix: %v", err)
+				}
+			}
+
+			var order []string
+			for !pq.IsEmpty() {
+				tk, _ := pq.Pop()
+				order = append(order, tk.name)
+			}
+			want := []string{"c", "b", "a", "d"}
+			for i := range want {
+				if order[i] != want[i] {
+					t.Fatalf("Pop order = %v, want %v", order, want)
+				}
+			}
+		})
+	}
+}
+
+// TestHandleSurvivesSort tests that handles follow their elements through Sort
+func TestHandleSurvivesSort(t *testing.T) {
+	strategies := []SortStrategy{
+		AutoStrategy, RadixStrategy, CountingStrategy, InsertionStrategy,
+		TimsortStrategy, IntrosortStrategy, MergeStrategy, QuickStrategy, PdqStrategy,
+	}
+
+	for _, strategy := range strategies {
+		pq := NewInts(nil)
+		handles := map[*Handle[int]]int{}
+		for i := 0; i < 200; i++ {
+			v := rand.Intn(50)
+			handles[pq.PushHandle(v)] = v
+		}
+		pq.SortWithStrategy(strategy)
+
+		sorted := pq.ToSlice()
+		if !sort.IntsAreSorted(sorted) {
+			t.Fatalf("Strategy %v: data not sorted", strategy)
+		}
+		for h, want := range handles {
+			if got, err := pq.Value(h); err != nil || got != want {
+				t.Fatalf("Strategy %v: Value = %d, %v, want %d", strategy, got, err, want)
+			}
+		}
+	}
+}
+
+// TestAutoHeapDecreaseKey tests that AutoHeap moves to the Fibonacci heap
+// under a decrease-key heavy workload
+func TestAutoHeapDecreaseKey(t *testing.T) {
+	pq := NewInts(nil, WithHeapKind(AutoHeap))
+	handles := make([]*Handle[int], 500)
+	for i := range handles {
+		handles[i] = pq.PushHandle(1000000 + i)
+	}
+
+	for i := 0; i < 4*autoHeapWindow; i++ {
+		h := handles[rand.Intn(len(handles))]
+		v, _ := pq.Value(h)
+		pq.Update(h, v-rand.Intn(100))
+	}
+	if pq.GetHeapKind() != FibonacciHeap {
+		t.Fatalf("Expected FibonacciHeap, got %v", pq.GetHeapKind())
+	}
+	assertHeap(t, pq)
+
+	for _, h := range handles {
+		if !h.Valid() {
+			t.Fatal("Expected handles to survive the backend switch")
+		}
+	}
+}
+
+// anyHandle returns an arbitrary key from a non-empty handle map
+func anyHandle(m map[*Handle[int]]int) *Handle[int] {
+	for h := range m {
+		return h
+	}
+	return nil
+}
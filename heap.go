@@ -0,0 +1,305 @@
+package pqueue
+
+// autoHeapWindow is the number of operations AutoHeap observes before
+// reconsidering which backend suits the workload
+const autoHeapWindow = 1024
+
+// heapOp identifies an operation counted towards the AutoHeap workload mix
+type heapOp int
+
+const (
+	opPush heapOp = iota
+	opPop
+	opUpdate
+)
+
+// opCounter tracks the operation mix seen during the current window
+type opCounter struct {
+	pushes  int
+	pops    int
+	updates int
+	pending HeapKind // preference from the previous window, used as hysteresis
+}
+
+// nodeHeap is implemented by the pointer-based backends
+type nodeHeap[T any] interface {
+	insert(n *heapNode[T])
+	min() *heapNode[T]
+	extractMin() *heapNode[T]
+	decreaseKey(n *heapNode[T])
+	remove(n *heapNode[T])
+	each(fn func(n *heapNode[T]) bool) // stops early when fn returns false
+	meld(other nodeHeap[T])            // other must be the same backend; it is left empty
+}
+
+// heapNode is an element of a pointer-based heap. Pairing heaps use left
+// for the previous sibling (or the parent of a first child) and right for
+// the next sibling; Fibonacci heaps keep left and right as a circular list.
+type heapNode[T any] struct {
+	value  T
+	parent *heapNode[T]
+	child  *heapNode[T]
+	left   *heapNode[T]
+	right  *heapNode[T]
+	degree int
+	marked bool
+	handle *Handle[T]
+}
+
+// newNode creates a detached node, binding it to h when h is not nil
+func newNode[T any](value T, h *Handle[T]) *heapNode[T] {
+	n := &heapNode[T]{value: value, handle: h}
+	if h != nil {
+		h.node = n
+		h.index = -1
+	}
+	return n
+}
+
+// releaseNode invalidates the handle bound to a node leaving the heap and
+// returns the node's value
+func releaseNode[T any](n *heapNode[T]) T {
+	if n.handle != nil {
+		n.handle.node = nil
+		n.handle = nil
+	}
+	return n.value
+}
+
+// useHeap installs the given backend over the elements currently in data.
+// kind must be a concrete backend, not AutoHeap.
+func (pq *PQueue[T]) useHeap(kind HeapKind) {
+	pq.heapKind = kind
+	pq.nodes = nil
+	pq.arity = 2
+
+	switch kind {
+	case QuaternaryHeap:
+		pq.arity = 4
+	case PairingHeap:
+		pq.nodes = &pairingHeap[T]{less: pq.less}
+	case FibonacciHeap:
+		pq.nodes = &fibonacciHeap[T]{less: pq.less}
+	}
+
+	if pq.nodes == nil {
+		pq.buildHeap()
+		return
+	}
+
+	// Pairing heaps are filled back to front and Fibonacci heaps front to
+	// back, so that a heap built from sorted data is walked in sorted order
+	// and ToSlice stays sorted after Sort
+	for j := 0; j < pq.size; j++ {
+		i := j
+		if kind == PairingHeap {
+			i = pq.size - 1 - j
+		}
+		var h *Handle[T]
+		if pq.refs != nil {
+			h = pq.refs[i]
+		}
+		pq.nodes.insert(newNode(pq.data[i], h))
+	}
+	pq.data = nil
+	pq.refs = nil
+}
+
+// flatten moves the contents of a node backend into data so array-based
+// algorithms can run over them. It reports whether the queue was node-backed;
+// data is then in no particular order and the caller must call useHeap.
+func (pq *PQueue[T]) flatten() bool {
+	if pq.nodes == nil {
+		return false
+	}
+	data := make([]T, 0, pq.size)
+	var refs []*Handle[T]
+	pq.nodes.each(func(n *heapNode[T]) bool {
+		if n.handle != nil && refs == nil {
+			refs = make([]*Handle[T], len(data), pq.size)
+		}
+		if refs != nil {
+			refs = append(refs, n.handle)
+		}
+		data = append(data, n.value)
+		return true
+	})
+	pq.data = data
+	pq.refs = refs
+	pq.nodes = nil
+	for i, h := range refs {
+		if h != nil {
+			h.node = nil
+			h.index = i
+		}
+	}
+	return true
+}
+
+// record counts an operation for AutoHeap and switches backend once a full
+// window suggests the same better fit twice in a row
+func (pq *PQueue[T]) record(op heapOp) {
+	if pq.opts.heapKind != AutoHeap {
+		return
+	}
+
+	switch op {
+	case opPush:
+		pq.ops.pushes++
+	case opPop:
+		pq.ops.pops++
+	case opUpdate:
+		pq.ops.updates++
+	}
+	if pq.ops.pushes+pq.ops.pops+pq.ops.updates < autoHeapWindow {
+		return
+	}
+
+	kind := pq.ops.preferredHeap(pq.size)
+	confirmed := kind == pq.ops.pending
+	pq.ops = opCounter{pending: kind}
+	if confirmed && kind != pq.heapKind {
+		pq.flatten()
+		pq.useHeap(kind)
+	}
+}
+
+// preferredHeap picks the backend best suited to the observed operation mix
+func (c opCounter) preferredHeap(size int) HeapKind {
+	// Decrease-key heavy workloads benefit from O(1) amortized decrease-key
+	if 2*c.updates >= c.pushes+c.pops+c.updates {
+		return FibonacciHeap
+	}
+
+	// Push-heavy workloads benefit from O(1) inserts that defer the work
+	if c.pushes >= 4*c.pops {
+		return PairingHeap
+	}
+
+	// Large pop-heavy heaps benefit from the shallower 4-ary tree
+	if size >= 4096 {
+		return QuaternaryHeap
+	}
+
+	return BinaryHeap
+}
+
+// buildHeap arranges the queue data into a valid d-ary min-heap in O(n)
+func (pq *PQueue[T]) buildHeap() {
+	if pq.size < 2 {
+		return
+	}
+	for i := (pq.size - 2) / pq.arity; i >= 0; i-- {
+		pq.siftDown(i)
+	}
+}
+
+// setRef records h as the handle of the element at index i
+func (pq *PQueue[T]) setRef(i int, h *Handle[T]) {
+	pq.refs[i] = h
+	if h != nil {
+		h.index = i
+	}
+}
+
+// siftUp moves the element at index i towards the root until its parent
+// is no greater than it. It reports whether the element moved.
+func (pq *PQueue[T]) siftUp(i int) bool {
+	start := i
+	item := pq.data[i]
+	var ref *Handle[T]
+	if pq.refs != nil {
+		ref = pq.refs[i]
+	}
+
+	for i > 0 {
+		parent := (i - 1) / pq.arity
+		if !pq.less(item, pq.data[parent]) {
+			break
+		}
+		pq.data[i] = pq.data[parent]
+		if pq.refs != nil {
+			pq.setRef(i, pq.refs[parent])
+		}
+		i = parent
+	}
+
+	pq.data[i] = item
+	if pq.refs != nil {
+		pq.setRef(i, ref)
+	}
+	return i != start
+}
+
+// siftDown moves the element at index i towards the leaves until none of
+// its children is smaller than it
+func (pq *PQueue[T]) siftDown(i int) {
+	item := pq.data[i]
+	var ref *Handle[T]
+	if pq.refs != nil {
+		ref = pq.refs[i]
+	}
+
+	for {
+		first := pq.arity*i + 1
+		if first >= pq.size {
+			break
+		}
+
+		// Find the smallest child
+		child := first
+		last := min(first+pq.arity, pq.size)
+		for c := first + 1; c < last; c++ {
+			if pq.less(pq.data[c], pq.data[child]) {
+				child = c
+			}
+		}
+
+		if !pq.less(pq.data[child], item) {
+			break
+		}
+		pq.data[i] = pq.data[child]
+		if pq.refs != nil {
+			pq.setRef(i, pq.refs[child])
+		}
+		i = child
+	}
+
+	pq.data[i] = item
+	if pq.refs != nil {
+		pq.setRef(i, ref)
+	}
+}
+
+// fix restores the heap property after the element at index i changed
+func (pq *PQueue[T]) fix(i int) {
+	if !pq.siftUp(i) {
+		pq.siftDown(i)
+	}
+}
+
+// removeAt removes and returns the element at index i of the implicit heap,
+// invalidating its handle
+func (pq *PQueue[T]) removeAt(i int) T {
+	var zero T
+	result := pq.data[i]
+	last := pq.size - 1
+
+	if pq.refs != nil {
+		removed := pq.refs[i]
+		pq.setRef(i, pq.refs[last])
+		pq.refs[last] = nil
+		if removed != nil {
+			removed.index = -1
+		}
+	}
+
+	// Move the last element into the hole and restore the heap property
+	pq.data[i] = pq.data[last]
+	pq.data[last] = zero // release the reference held by the vacated slot
+	pq.size--
+	if i < pq.size {
+		pq.fix(i)
+	}
+	return result
+}
@@ -0,0 +1,211 @@
+package pqueue
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+var allHeapKinds = []struct {
+	name string
+	kind HeapKind
+}{
+	{"Binary", BinaryHeap},
+	{"Quaternary", QuaternaryHeap},
+	{"Pairing", PairingHeap},
+	{"Fibonacci", FibonacciHeap},
+	{"Auto", AutoHeap},
+}
+
+// assertHeap fails the test if the queue data violates the min-heap property
+func assertHeap[T any](t *testing.T, pq *PQueue[T]) {
+	t.Helper()
+	if pq.nodes != nil {
+		count := 0
+		pq.nodes.each(func(n *heapNode[T]) bool {
+			count++
+			for c := n.child; c != nil; {
+				if pq.less(c.value, n.value) {
+					t.Fatalf("heap property violated: child %v < parent %v", c.value, n.value)
+				}
+				c = c.right
+				if c == n.child {
+					break
+				}
+			}
+			return true
+		})
+		if count != pq.size {
+			t.Fatalf("node heap holds %d elements, size is %d", count, pq.size)
+		}
+		return
+	}
+	for i := 1; i < pq.size; i++ {
+		parent := (i - 1) / pq.arity
+		if pq.less(pq.data[i], pq.data[parent]) {
+			t.Fatalf("heap property violated: data[%d] < data[%d]", i, parent)
+		}
+	}
+}
+
+// TestHeapConstruction tests that New heapifies its input
+func TestHeapConstruction(t *testing.T) {
+	inputs := [][]int{
+		{},
+		{1},
+		{2, 1},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1},
+		{5, 5, 5, 1, 1, 9, 0, 3},
+	}
+
+	for _, data := range inputs {
+		pq := NewInts(data)
+		assertHeap(t, pq)
+	}
+}
+
+// TestHeapPopOrder tests that Pop returns elements in ascending order
+func TestHeapPopOrder(t *testing.T) {
+	for _, hk := range allHeapKinds {
+		t.Run(hk.name, func(t *testing.T) {
+			data := make([]int, 500)
+			for i := range data {
+				data[i] = rand.Intn(100)
+			}
+
+			pq := NewInts(data, WithHeapKind(hk.kind))
+			assertHeap(t, pq)
+			expected := make([]int, len(data))
+			copy(expected, data)
+			sort.Ints(expected)
+
+			for i, want := range expected {
+				got, err := pq.Pop()
+				if err != nil {
+					t.Fatalf("Unexpected error at pop %d: %v", i, err)
+				}
+				if got != want {
+					t.Fatalf("Pop %d = %d, want %d", i, got, want)
+				}
+			}
+			if !pq.IsEmpty() {
+				t.Errorf("Expected queue to be empty, size %d", pq.Size())
+			}
+			if _, err := pq.Pop(); err == nil {
+				t.Error("Expected error when popping from empty queue")
+			}
+		})
+	}
+}
+
+// TestHeapInterleavedOperations tests mixed Push and Pop against a reference
+func TestHeapInterleavedOperations(t *testing.T) {
+	for _, hk := range allHeapKinds {
+		t.Run(hk.name, func(t *testing.T) {
+			pq := NewInts(nil, WithHeapKind(hk.kind))
+			var reference []int
+
+			for i := 0; i < 5000; i++ {
+				if len(reference) == 0 || rand.Intn(3) > 0 {
+					v := rand.Intn(1000)
+					pq.Push(v)
+					reference = append(reference, v)
+					continue
+				}
+
+				sort.Ints(reference)
+				want := reference[0]
+				reference = reference[1:]
+
+				peeked, _ := pq.Peek()
+				got, err := pq.Pop()
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if got != want || peeked != want {
+					t.Fatalf("Pop = %d, Peek = %d, want %d", got, peeked, want)
+				}
+			}
+
+			assertHeap(t, pq)
+			if pq.Size() != len(reference) {
+				t.Errorf("Expected size %d, got %d", len(reference), pq.Size())
+			}
+		})
+	}
+}
+
+// TestAutoHeapSelection tests that AutoHeap follows the operation mix
+func TestAutoHeapSelection(t *testing.T) {
+	pq := NewInts(nil, WithHeapKind(AutoHeap))
+	if pq.GetHeapKind() != BinaryHeap {
+		t.Fatalf("Expected AutoHeap to start as BinaryHeap, got %v", pq.GetHeapKind())
+	}
+
+	// A push-only workload should move to the pairing heap
+	for i := 0; i < 5*autoHeapWindow; i++ {
+		pq.Push(rand.Intn(100000))
+	}
+	if pq.GetHeapKind() != PairingHeap {
+		t.Fatalf("Expected PairingHeap after pushes, got %v", pq.GetHeapKind())
+	}
+	assertHeap(t, pq)
+
+	// A balanced workload on a large heap should move to the 4-ary heap
+	for i := 0; i < 3*autoHeapWindow; i++ {
+		pq.Push(rand.Intn(100000))
+		pq.Pop()
+	}
+	if pq.GetHeapKind() != QuaternaryHeap {
+		t.Fatalf("Expected QuaternaryHeap after balanced operations, got %v", pq.GetHeapKind())
+	}
+	assertHeap(t, pq)
+
+	prev, _ := pq.Pop()
+	for !pq.IsEmpty() {
+		next, _ := pq.Pop()
+		if next < prev {
+			t.Fatalf("Pop order violated after backend switches: %d < %d", next, prev)
+		}
+		prev = next
+	}
+}
+
+// TestHeapAfterSort tests that every strategy leaves a valid heap behind
+func TestHeapAfterSort(t *testing.T) {
+	strategies := []SortStrategy{
+		AutoStrategy,
+		RadixStrategy,
+		CountingStrategy,
+		InsertionStrategy,
+		TimsortStrategy,
+		IntrosortStrategy,
+		MergeStrategy,
+		QuickStrategy,
+		PdqStrategy,
+		ParallelMergeStrategy,
+	}
+
+	for _, hk := range allHeapKinds {
+		for _, strategy := range strategies {
+			data := make([]int, 300)
+			for i := range data {
+				data[i] = rand.Intn(1000)
+			}
+
+			pq := NewInts(data, WithHeapKind(hk.kind))
+			pq.SortWithStrategy(strategy)
+			assertHeap(t, pq)
+			if !sort.IntsAreSorted(pq.ToSlice()) {
+				t.Errorf("%s heap, strategy %v: ToSlice not sorted after Sort", hk.name, strategy)
+			}
+
+			pq.Push(-1)
+			pq.Push(2000)
+			if min, _ := pq.Pop(); min != -1 {
+				t.Errorf("%s heap, strategy %v: expected -1 after sort and push, got %d", hk.name, strategy, min)
+			}
+			assertHeap(t, pq)
+		}
+	}
+}
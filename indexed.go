@@ -0,0 +1,123 @@
+package pqueue
+
+// IndexedPQueue is a priority queue whose elements are identified by
+// caller-supplied keys. Each key holds at most one element, and the element
+// for a key can be read, replaced or deleted in O(log n).
+type IndexedPQueue[K comparable, T any] struct {
+	queue   *PQueue[entry[K, T]]
+	handles map[K]*Handle[entry[K, T]]
+}
+
+// entry is a keyed element stored in an IndexedPQueue
+type entry[K comparable, T any] struct {
+	key   K
+	value T
+}
+
+// NewIndexed creates an empty IndexedPQueue ordered by less, which follows
+// the same contract as the comparison function passed to New
+func NewIndexed[K comparable, T any](less func(T, T) bool, opts ...Option) *IndexedPQueue[K, T] {
+	return &IndexedPQueue[K, T]{
+		queue: New(nil, func(a, b entry[K, T]) bool {
+			return less(a.value, b.value)
+		}, opts...),
+		handles: make(map[K]*Handle[entry[K, T]]),
+	}
+}
+
+// Size returns the number of elements in the queue
+func (q *IndexedPQueue[K, T]) Size() int {
+	return q.queue.Size()
+}
+
+// IsEmpty returns true if the queue is empty
+func (q *IndexedPQueue[K, T]) IsEmpty() bool {
+	return q.queue.IsEmpty()
+}
+
+// Set stores value under key, replacing and reordering any existing element
+// for that key
+func (q *IndexedPQueue[K, T]) Set(key K, value T) {
+	e := entry[K, T]{key: key, value: value}
+	if h, ok := q.handles[key]; ok {
+		q.queue.Update(h, e)
+		return
+	}
+	q.handles[key] = q.queue.PushHandle(e)
+}
+
+// Get returns the element stored under key
+func (q *IndexedPQueue[K, T]) Get(key K) (T, bool) {
+	h, ok := q.handles[key]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	e, _ := q.queue.Value(h)
+	return e.value, true
+}
+
+// Contains reports whether an element is stored under key
+func (q *IndexedPQueue[K, T]) Contains(key K) bool {
+	_, ok := q.handles[key]
+	return ok
+}
+
+// Delete removes the element stored under key and reports whether it existed
+func (q *IndexedPQueue[K, T]) Delete(key K) bool {
+	h, ok := q.handles[key]
+	if !ok {
+		return false
+	}
+	q.queue.Remove(h)
+	delete(q.handles, key)
+	return true
+}
+
+// PeekWithKey returns the smallest element and its key without removing it
+func (q *IndexedPQueue[K, T]) PeekWithKey() (K, T, error) {
+	e, err := q.queue.Peek()
+	return e.key, e.value, err
+}
+
+// PopWithKey removes and returns the smallest element and its key
+func (q *IndexedPQueue[K, T]) PopWithKey() (K, T, error) {
+	e, err := q.queue.Pop()
+	if err != nil {
+		return e.key, e.value, err
+	}
+	delete(q.handles, e.key)
+	return e.key, e.value, nil
+}
+
+// Sort sorts the queue using the optimal algorithm based on data characteristics
+func (q *IndexedPQueue[K, T]) Sort() {
+	q.queue.Sort()
+}
+
+// SortWithStrategy sorts using a specific strategy
+func (q *IndexedPQueue[K, T]) SortWithStrategy(strategy SortStrategy) {
+	q.queue.SortWithStrategy(strategy)
+}
+
+// Keys returns the keys in the queue's current order, which is ascending
+// by element after Sort
+func (q *IndexedPQueue[K, T]) Keys() []K {
+	entries := q.queue.ToSlice()
+	keys := make([]K, len(entries))
+	for i, e := range entries {
+		keys[i] = e.key
+	}
+	return keys
+}
+
+// ToSlice returns the elements in the queue's current order, which is
+// ascending after Sort
+func (q *IndexedPQueue[K, T]) ToSlice() []T {
+	entries := q.queue.ToSlice()
+	values := make([]T, len(entries))
+	for i, e := range entries {
+		values[i] = e.value
+	}
+	return values
+}
@@ -0,0 +1,141 @@
+package pqueue
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// TestIndexedBasicOperations tests Set, Get, Contains, Delete and PopWithKey
+func TestIndexedBasicOperations(t *testing.T) {
+	q := NewIndexed[string](func(a, b int) bool { return a < b })
+
+	q.Set("a", 5)
+	q.Set("b", 3)
+	q.Set("c", 8)
+	q.Set("a", 1) // update moves a to the front
+
+	if q.Size() != 3 {
+		t.Errorf("Expected size 3, got %d", q.Size())
+	}
+	if v, ok := q.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) = %d, %v, want 1, true", v, ok)
+	}
+	if _, ok := q.Get("z"); ok {
+		t.Error("Expected Get on a missing key to fail")
+	}
+	if !q.Contains("c") || q.Contains("z") {
+		t.Error("Contains reported the wrong membership")
+	}
+
+	if !q.Delete("b") {
+		t.Error("Expected Delete(b) to succeed")
+	}
+	if q.Delete("b") {
+		t.Error("Expected second Delete(b) to fail")
+	}
+
+	key, value, err := q.PopWithKey()
+	if err != nil || key != "a" || value != 1 {
+		t.Errorf("PopWithKey = %s, %d, %v, want a, 1, nil", key, value, err)
+	}
+	if q.Contains("a") {
+		t.Error("Expected popped key to be gone")
+	}
+
+	key, value, err = q.PeekWithKey()
+	if err != nil || key != "c" || value != 8 {
+		t.Errorf("PeekWithKey = %s, %d, %v, want c, 8, nil", key, value, err)
+	}
+
+	q.PopWithKey()
+	if _, _, err := q.PopWithKey(); err != ErrEmpty {
+		t.Errorf("Expected ErrEmpty, got %v", err)
+	}
+}
+
+// TestIndexedSort tests that Sort orders keys and values by element
+func TestIndexedSort(t *testing.T) {
+	for _, hk := range allHeapKinds {
+		t.Run(hk.name, func(t *testing.T) {
+			q := NewIndexed[int](func(a, b string) bool { return a < b }, WithHeapKind(hk.kind))
+			q.Set(1, "delta")
+			q.Set(2, "alpha")
+			q.Set(3, "charlie")
+			q.Set(4, "bravo")
+			q.SortWithStrategy(MergeStrategy)
+
+			if got, want := q.Keys(), []int{2, 4, 3, 1}; !reflect.DeepEqual(got, want) {
+				t.Errorf("Keys() = %v, want %v", got, want)
+			}
+			if got, want := q.ToSlice(), []string{"alpha", "bravo", "charlie", "delta"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("ToSlice() = %v, want %v", got, want)
+			}
+
+			// The queue must stay consistent after sorting
+			q.Set(1, "aardvark")
+			if key, _, _ := q.PopWithKey(); key != 1 {
+				t.Errorf("Expected key 1 after update, got %d", key)
+			}
+		})
+	}
+}
+
+// TestIndexedDijkstra tests the queue in a shortest path computation
+func TestIndexedDijkstra(t *testing.T) {
+	// Random dense graph with non-negative weights
+	const n = 60
+	weight := make([][]int, n)
+	for i := range weight {
+		weight[i] = make([]int, n)
+		for j := range weight[i] {
+			weight[i][j] = rand.Intn(100) + 1
+		}
+	}
+
+	// Reference distances via Bellman-Ford style relaxation
+	want := make([]int, n)
+	for i := range want {
+		want[i] = 1 << 30
+	}
+	want[0] = 0
+	for changed := true; changed; {
+		changed = false
+		for u := 0; u < n; u++ {
+			for v := 0; v < n; v++ {
+				if want[u]+weight[u][v] < want[v] {
+					want[v] = want[u] + weight[u][v]
+					changed = true
+				}
+			}
+		}
+	}
+
+	for _, hk := range allHeapKinds {
+		t.Run(hk.name, func(t *testing.T) {
+			dist := make([]int, n)
+			for i := range dist {
+				dist[i] = -1
+			}
+
+			q := NewIndexed[int](func(a, b int) bool { return a < b }, WithHeapKind(hk.kind))
+			q.Set(0, 0)
+			for !q.IsEmpty() {
+				u, d, _ := q.PopWithKey()
+				dist[u] = d
+				for v := 0; v < n; v++ {
+					if dist[v] >= 0 {
+						continue
+					}
+					if cur, ok := q.Get(v); !ok || d+weight[u][v] < cur {
+						q.Set(v, d+weight[u][v])
+					}
+				}
+			}
+
+			if !reflect.DeepEqual(dist, want) {
+				t.Errorf("Dijkstra distances = %v, want %v", dist, want)
+			}
+		})
+	}
+}
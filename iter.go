@@ -0,0 +1,129 @@
+package pqueue
+
+import "iter"
+
+// errModified is the panic value raised by All and Sorted when the queue
+// changes while they are iterating
+const errModified = "pqueue: queue modified during iteration"
+
+// All returns an iterator over the elements in no particular order,
+// without copying them. The queue must not be modified while the iterator
+// is in use; All panics if it detects a modification.
+func (pq *PQueue[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		mods := pq.mods
+		if pq.nodes != nil {
+			pq.nodes.each(func(n *heapNode[T]) bool {
+				if pq.mods != mods {
+					panic(errModified)
+				}
+				return yield(n.value)
+			})
+			return
+		}
+
+		for i := 0; i < pq.size; i++ {
+			if pq.mods != mods {
+				panic(errModified)
+			}
+			if !yield(pq.data[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Drain returns an iterator that pops elements in priority order until the
+// queue is empty. Each element is popped only when it is requested, so
+// breaking out of the loop leaves the rest in the queue. Elements pushed
+// during iteration are yielded in order with the others.
+func (pq *PQueue[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			item, err := pq.Pop()
+			if err != nil || !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Sorted returns an iterator over the elements in priority order that
+// leaves the queue unchanged. On array backends reading the first k
+// elements costs O(k log k) by walking the heap from its root. Pairing and
+// Fibonacci roots may have O(n) children, so there even the first element
+// can cost O(n log n). The queue must not be
+// modified while the iterator is in use; Sorted panics if it detects a
+// modification.
+func (pq *PQueue[T]) Sorted() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if pq.size == 0 {
+			return
+		}
+		if pq.nodes != nil {
+			pq.sortedNodes(yield)
+			return
+		}
+
+		// The frontier holds indices of elements whose parents were yielded
+		mods := pq.mods
+		frontier := &PQueue[int]{
+			less:     func(a, b int) bool { return pq.less(pq.data[a], pq.data[b]) },
+			dataType: GenericType,
+			arity:    2,
+		}
+		frontier.Push(0)
+		for !frontier.IsEmpty() {
+			if pq.mods != mods {
+				panic(errModified)
+			}
+			i, _ := frontier.Pop()
+			first := pq.arity*i + 1
+			for c := first; c < first+pq.arity && c < pq.size; c++ {
+				frontier.Push(c)
+			}
+			if !yield(pq.data[i]) {
+				return
+			}
+		}
+	}
+}
+
+// sortedNodes yields the elements of a node backend in priority order
+func (pq *PQueue[T]) sortedNodes(yield func(T) bool) {
+	mods := pq.mods
+	var seen []*heapNode[T]
+	frontier := &PQueue[int]{
+		less:     func(a, b int) bool { return pq.less(seen[a].value, seen[b].value) },
+		dataType: GenericType,
+		arity:    2,
+	}
+
+	// add pushes n and its right siblings, following both nil-terminated
+	// and circular sibling lists
+	add := func(n *heapNode[T]) {
+		for s := n; s != nil; {
+			seen = append(seen, s)
+			frontier.Push(len(seen) - 1)
+			s = s.right
+			if s == n {
+				break
+			}
+		}
+	}
+
+	add(pq.nodes.min())
+	for !frontier.IsEmpty() {
+		if pq.mods != mods {
+			panic(errModified)
+		}
+		i, _ := frontier.Pop()
+		n := seen[i]
+		if n.child != nil {
+			add(n.child)
+		}
+		if !yield(n.value) {
+			return
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package pqueue
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+)
+
+// TestIterAll tests that All visits every element once without copying
+func TestIterAll(t *testing.T) {
+	for _, hk := range allHeapKinds {
+		t.Run(hk.name, func(t *testing.T) {
+			data := make([]int, 200)
+			for i := range data {
+				data[i] = rand.Intn(100)
+			}
+			pq := NewInts(data, WithHeapKind(hk.kind))
+
+			got := slices.Collect(pq.All())
+			sort.Ints(got)
+			want := append([]int(nil), data...)
+			sort.Ints(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("All() visited %v, want %v", got, want)
+			}
+
+			count := 0
+			for range pq.All() {
+				count++
+				if count == 10 {
+					break
+				}
+			}
+			if count != 10 || pq.Size() != len(data) {
+				t.Errorf("Early break visited %d elements, size %d", count, pq.Size())
+			}
+		})
+	}
+}
+
+// TestIterSorted tests that Sorted yields in order and leaves the queue
+// unchanged
+func TestIterSorted(t *testing.T) {
+	for _, hk := range allHeapKinds {
+		t.Run(hk.name, func(t *testing.T) {
+			pq := NewInts(nil, WithHeapKind(hk.kind))
+			var want []int
+			for i := 0; i < 500; i++ {
+				v := rand.Intn(1000)
+				pq.Push(v)
+				want = append(want, v)
+			}
+			// Pops restructure the node backends before iterating
+			for i := 0; i < 100; i++ {
+				pq.Pop()
+			}
+			sort.Ints(want)
+			want = want[100:]
+
+			before := pq.ToSlice()
+			if got := slices.Collect(pq.Sorted()); !reflect.DeepEqual(got, want) {
+				t.Errorf("Sorted() = %v, want %v", got, want)
+			}
+			if !reflect.DeepEqual(pq.ToSlice(), before) {
+				t.Error("Sorted() modified the queue")
+			}
+
+			var first []int
+			for v := range pq.Sorted() {
+				first = append(first, v)
+				if len(first) == 5 {
+					break
+				}
+			}
+			if !reflect.DeepEqual(first, want[:5]) {
+				t.Errorf("First five = %v, want %v", first, want[:5])
+			}
+		})
+	}
+}
+
+// TestIterDrain tests lazy popping, early termination and pushes during
+// iteration
+func TestIterDrain(t *testing.T) {
+	pq := NewInts([]int{5, 1, 4, 2, 3})
+
+	var got []int
+	for v := range pq.Drain() {
+		got = append(got, v)
+		if v == 2 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(got, []int{1, 2}) || pq.Size() != 3 {
+		t.Errorf("Drain until 2 = %v with size %d", got, pq.Size())
+	}
+
+	got = got[:0]
+	for v := range pq.Drain() {
+		got = append(got, v)
+		if v == 3 {
+			pq.Push(0)
+		}
+	}
+	if !reflect.DeepEqual(got, []int{3, 0, 4, 5}) || !pq.IsEmpty() {
+		t.Errorf("Drain with push = %v with size %d", got, pq.Size())
+	}
+}
+
+// TestIterModification tests that All and Sorted panic when the queue is
+// modified during iteration
+func TestIterModification(t *testing.T) {
+	iterators := map[string]func(pq *PQueue[int]) func(func(int) bool){
+		"All":    func(pq *PQueue[int]) func(func(int) bool) { return pq.All() },
+		"Sorted": func(pq *PQueue[int]) func(func(int) bool) { return pq.Sorted() },
+	}
+
+	for name, seq := range iterators {
+		for _, hk := range allHeapKinds {
+			t.Run(name+"_"+hk.name, func(t *testing.T) {
+				pq := NewInts([]int{3, 1, 2, 5, 4}, WithHeapKind(hk.kind))
+				defer func() {
+					if r := recover(); r != errModified {
+						t.Errorf("Expected panic %q, got %v", errModified, r)
+					}
+				}()
+				for range seq(pq) {
+					pq.Push(0)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package pqueue
+
+import "iter"
+
+// DedupMode selects which of several equal elements a k-way merge keeps
+type DedupMode int
+
+const (
+	DedupNone      DedupMode = iota // keep every element
+	DedupKeepFirst                  // keep the first of equal elements, from the lowest source
+	DedupKeepLast                   // keep the last of equal elements, from the highest source
+)
+
+// mergeHead is the smallest unmerged element of a source
+type mergeHead[T any] struct {
+	value  T
+	source int
+}
+
+// MergeSorted returns an iterator over the elements of sorted sources in
+// the order of less. Equal elements are yielded by source index, and in
+// source order within a source, so the merge is stable. Each source is
+// read only as far as the consumer iterates, and breaking out of the loop
+// stops them all.
+func MergeSorted[T any](less func(T, T) bool, sources ...iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		next := make([]func() (T, bool), len(sources))
+		for i, src := range sources {
+			var stop func()
+			next[i], stop = iter.Pull(src)
+			defer stop()
+		}
+
+		heads := New(nil, func(a, b mergeHead[T]) bool {
+			if less(a.value, b.value) {
+				return true
+			}
+			return !less(b.value, a.value) && a.source < b.source
+		})
+		for i := range next {
+			if v, ok := next[i](); ok {
+				heads.Push(mergeHead[T]{value: v, source: i})
+			}
+		}
+
+		for !heads.IsEmpty() {
+			head, _ := heads.Pop()
+			if !yield(head.value) {
+				return
+			}
+			if v, ok := next[head.source](); ok {
+				heads.Push(mergeHead[T]{value: v, source: head.source})
+			}
+		}
+	}
+}
+
+// MergeSortedDedup is MergeSorted collapsing each run of equal elements
+// into the one mode selects
+func MergeSortedDedup[T any](less func(T, T) bool, mode DedupMode, sources ...iter.Seq[T]) iter.Seq[T] {
+	merged := MergeSorted(less, sources...)
+	if mode == DedupNone {
+		return merged
+	}
+
+	return func(yield func(T) bool) {
+		var kept T
+		pending := false
+		for v := range merged {
+			if pending && !less(kept, v) {
+				if mode == DedupKeepLast {
+					kept = v
+				}
+				continue
+			}
+			if pending && !yield(kept) {
+				return
+			}
+			kept, pending = v, true
+		}
+		if pending {
+			yield(kept)
+		}
+	}
+}
+
+// MergeSortedSlices merges sorted slices into a new slice, stably as
+// MergeSorted does
+func MergeSortedSlices[T any](less func(T, T) bool, sources ...[]T) []T {
+	return MergeSortedSlicesDedup(less, DedupNone, sources...)
+}
+
+// MergeSortedSlicesDedup is MergeSortedSlices collapsing each run of equal
+// elements into the one mode selects
+func MergeSortedSlicesDedup[T any](less func(T, T) bool, mode DedupMode, sources ...[]T) []T {
+	total := 0
+	for _, s := range sources {
+		total += len(s)
+	}
+	out := make([]T, 0, total)
+
+	// The heap holds source indices ordered by their next element
+	pos := make([]int, len(sources))
+	heads := &PQueue[int]{
+		less: func(a, b int) bool {
+			x, y := sources[a][pos[a]], sources[b][pos[b]]
+			if less(x, y) {
+				return true
+			}
+			return !less(y, x) && a < b
+		},
+		dataType: GenericType,
+		arity:    2,
+	}
+	for i, s := range sources {
+		if len(s) > 0 {
+			heads.Push(i)
+		}
+	}
+
+	for !heads.IsEmpty() {
+		i, _ := heads.Pop()
+		v := sources[i][pos[i]]
+		pos[i]++
+		if pos[i] < len(sources[i]) {
+			heads.Push(i)
+		}
+
+		if n := len(out); mode != DedupNone && n > 0 && !less(out[n-1], v) {
+			if mode == DedupKeepLast {
+				out[n-1] = v
+			}
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+package pqueue
+
+import (
+	"iter"
+	"slices"
+	"testing"
+)
+
+// sortedSources returns k sorted slices of keyed elements, with keys shared
+// within and across sources and indices numbering the sources in order
+func sortedSources(k, n int) [][]keyed {
+	sources := make([][]keyed, k)
+	index := 0
+	for i := range sources {
+		src := keyedInput(n*(i%3), 20, "ascending") // some sources are empty
+		for j := range src {
+			src[j].index = index
+			index++
+		}
+		sources[i] = src
+	}
+	return sources
+}
+
+// dedupWant collapses runs of equal keys in a stably sorted slice
+func dedupWant(sorted []keyed, mode DedupMode) []keyed {
+	var out []keyed
+	for _, v := range sorted {
+		if n := len(out); n > 0 && out[n-1].key == v.key {
+			if mode == DedupKeepLast {
+				out[n-1] = v
+			}
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// TestMergeSorted tests that the iterator and slice merges are stable and
+// deduplicate as requested
+func TestMergeSorted(t *testing.T) {
+	less := func(a, b keyed) bool { return a.key < b.key }
+	for _, k := range []int{0, 1, 2, 5, 40} {
+		sources := sortedSources(k, 50)
+		stable := slices.Concat(sources...)
+		slices.SortStableFunc(stable, func(a, b keyed) int { return a.key - b.key })
+
+		seqs := make([]iter.Seq[keyed], k)
+		for i, s := range sources {
+			seqs[i] = slices.Values(s)
+		}
+
+		if got := slices.Collect(MergeSorted(less, seqs...)); !slices.Equal(got, stable) {
+			t.Errorf("%d sources: MergeSorted is not a stable merge", k)
+		}
+		if got := MergeSortedSlices(less, sources...); !slices.Equal(got, stable) {
+			t.Errorf("%d sources: MergeSortedSlices is not a stable merge", k)
+		}
+
+		for _, mode := range []DedupMode{DedupKeepFirst, DedupKeepLast} {
+			want := dedupWant(stable, mode)
+			if got := slices.Collect(MergeSortedDedup(less, mode, seqs...)); !slices.Equal(got, want) {
+				t.Errorf("%d sources, mode %v: MergeSortedDedup got %v, want %v", k, mode, got, want)
+			}
+			if got := MergeSortedSlicesDedup(less, mode, sources...); !slices.Equal(got, want) {
+				t.Errorf("%d sources, mode %v: MergeSortedSlicesDedup got %v, want %v", k, mode, got, want)
+			}
+		}
+	}
+}
+
+// TestMergeSortedEarlyExit tests that breaking out of the loop stops every
+// source and reads no further than needed
+func TestMergeSortedEarlyExit(t *testing.T) {
+	read, stopped := 0, 0
+	counting := func(values ...int) iter.Seq[int] {
+		return func(yield func(int) bool) {
+			defer func() { stopped++ }()
+			for _, v := range values {
+				read++
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+
+	sources := []iter.Seq[int]{counting(1, 4, 7, 10), counting(2, 5, 8), counting(3, 6, 9)}
+	var got []int
+	for v := range MergeSorted(func(a, b int) bool { return a < b }, sources...) {
+		got = append(got, v)
+		if len(got) == 4 {
+			break
+		}
+	}
+
+	if !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("Got %v", got)
+	}
+	if read != 6 { // the four yielded and the next heads of two sources
+		t.Errorf("Read %d elements, want 6", read)
+	}
+	if stopped != 3 {
+		t.Errorf("%d of 3 sources stopped", stopped)
+	}
+}
@@ -0,0 +1,156 @@
+package pqueue
+
+import (
+	"math/bits"
+	"reflect"
+)
+
+// Merge moves every element of other into pq, leaving other empty but
+// usable. Handles issued by other stay valid and now belong to pq. Two
+// pairing or two Fibonacci queues are joined in O(1); otherwise the array
+// backends are reheapified in O(n+m), or the m new elements sifted up when
+// that is cheaper.
+//
+// Both queues must order elements with the same less function, as when
+// they were created by the same constructor. Functions are compared by
+// their code only: two identical literals written in different places are
+// rejected with ErrIncompatibleLess, leaving both queues unchanged, but
+// closures from one literal are accepted even when they capture different
+// state, such as comparators built by a helper for different fields.
+// Merging such queues leaves an invalid heap; callers must not do it.
+// Merging a queue into itself has no effect.
+func (pq *PQueue[T]) Merge(other *PQueue[T]) error {
+	if other == nil || other == pq {
+		return nil
+	}
+	if !sameLess(pq.less, other.less) {
+		return ErrIncompatibleLess
+	}
+
+	n := pq.size
+	pq.absorb(other)
+	pq.restoreHeap(n)
+	return nil
+}
+
+// Meld combines the given queues into a new queue that uses the options and
+// heap backend of the first one. The inputs are left empty. Node backends
+// are joined in O(1) per queue and array backends heapified once in O(n).
+// Meld returns ErrEmpty if no queue is given and ErrIncompatibleLess, with
+// no queue changed, if the queues use different less functions. As with
+// Merge, closures from one literal count as the same function whatever
+// state they capture, so the caller must ensure they order alike.
+func Meld[T any](qs ...*PQueue[T]) (*PQueue[T], error) {
+	var first *PQueue[T]
+	for _, q := range qs {
+		if q == nil {
+			continue
+		}
+		if first == nil {
+			first = q
+		} else if !sameLess(first.less, q.less) {
+			return nil, ErrIncompatibleLess
+		}
+	}
+	if first == nil {
+		return nil, ErrEmpty
+	}
+
+	result := &PQueue[T]{
+		less:     first.less,
+		dataType: first.dataType,
+		opts:     first.opts,
+		natural:  first.natural,
+	}
+	result.useHeap(first.heapKind)
+	for _, q := range qs {
+		if q != nil {
+			result.absorb(q)
+		}
+	}
+	result.restoreHeap(0)
+	return result, nil
+}
+
+// sameLess reports whether two comparison functions share the same code.
+// Closures created by the same function literal compare equal even if
+// they capture different state.
+func sameLess[T any](a, b func(T, T) bool) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
+// absorb moves the elements of other into pq and resets other. Elements
+// moved into an array backend are appended to data without restoring the
+// heap order; the caller must call restoreHeap.
+func (pq *PQueue[T]) absorb(other *PQueue[T]) {
+	if other.size == 0 {
+		return
+	}
+	pq.mods++
+	other.mods++
+	if pq.dataType == GenericType {
+		pq.dataType = other.dataType
+	}
+
+	switch {
+	case pq.nodes != nil && other.nodes != nil && pq.heapKind == other.heapKind:
+		pq.nodes.meld(other.nodes)
+	case pq.nodes != nil:
+		other.flatten()
+		for i := 0; i < other.size; i++ {
+			var h *Handle[T]
+			if other.refs != nil {
+				h = other.refs[i]
+			}
+			pq.nodes.insert(newNode(other.data[i], h))
+		}
+	default:
+		other.flatten()
+		pq.appendData(other.data[:other.size], other.refs)
+	}
+
+	pq.size += other.size
+	other.size = 0
+	other.data = nil
+	other.refs = nil
+	other.useHeap(other.heapKind)
+}
+
+// appendData appends elements and their handles, which may be nil, after
+// the last element of an array backend without restoring the heap order
+func (pq *PQueue[T]) appendData(data []T, refs []*Handle[T]) {
+	if pq.refs == nil && refs != nil {
+		pq.refs = make([]*Handle[T], len(pq.data))
+	}
+
+	pq.data = append(pq.data[:pq.size], data...)
+	if pq.refs == nil {
+		return
+	}
+	if refs == nil {
+		refs = make([]*Handle[T], len(data))
+	}
+	pq.refs = append(pq.refs[:pq.size], refs[:len(data)]...)
+	for i := pq.size; i < len(pq.refs); i++ {
+		if h := pq.refs[i]; h != nil {
+			h.index = i
+		}
+	}
+}
+
+// restoreHeap restores the heap order of an array backend after elements
+// were appended from index n onwards
+func (pq *PQueue[T]) restoreHeap(n int) {
+	if pq.nodes != nil {
+		return
+	}
+
+	// Sifting up m elements costs O(m log size), rebuilding costs O(size)
+	if m := pq.size - n; m*bits.Len(uint(pq.size)) < pq.size {
+		for i := n; i < pq.size; i++ {
+			pq.siftUp(i)
+		}
+		return
+	}
+	pq.buildHeap()
+}
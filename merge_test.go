@@ -0,0 +1,166 @@
+package pqueue
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// TestMergeAllHeapKinds tests merging between every pair of backends
+func TestMergeAllHeapKinds(t *testing.T) {
+	for _, dst := range allHeapKinds {
+		for _, src := range allHeapKinds {
+			t.Run(dst.name+"_"+src.name, func(t *testing.T) {
+				a := NewInts(nil, WithHeapKind(dst.kind))
+				b := NewInts(nil, WithHeapKind(src.kind))
+				var want []int
+				for i := 0; i < 300; i++ {
+					v := rand.Intn(1000)
+					a.Push(v)
+					want = append(want, v)
+				}
+				for i := 0; i < 200; i++ {
+					v := rand.Intn(1000)
+					b.Push(v)
+					want = append(want, v)
+				}
+				h := b.PushHandle(5000)
+				want = append(want, -1)
+
+				if err := a.Merge(b); err != nil {
+					t.Fatalf("Merge: %v", err)
+				}
+				if !b.IsEmpty() {
+					t.Errorf("Expected merged queue to be empty, got size %d", b.Size())
+				}
+				if a.Size() != len(want) {
+					t.Fatalf("Expected size %d, got %d", len(want), a.Size())
+				}
+
+				// The handle moved with its element
+				if err := a.Update(h, -1); err != nil {
+					t.Fatalf("Update through moved handle: %v", err)
+				}
+				assertHeap(t, a)
+
+				sort.Ints(want)
+				for i, w := range want {
+					if v, err := a.Pop(); err != nil || v != w {
+						t.Fatalf("Pop %d = %d, %v, want %d", i, v, err, w)
+					}
+				}
+
+				// The emptied source is still usable
+				b.Push(3)
+				if v, err := b.Pop(); err != nil || v != 3 {
+					t.Errorf("Pop after merge = %d, %v, want 3", v, err)
+				}
+			})
+		}
+	}
+}
+
+// TestMergeSmallIntoLarge tests the sift-up path for small merges
+func TestMergeSmallIntoLarge(t *testing.T) {
+	data := make([]int, 5000)
+	for i := range data {
+		data[i] = rand.Intn(10000)
+	}
+	a := NewInts(data)
+	b := NewInts([]int{-5, 20000, 7})
+
+	if err := a.Merge(b); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	assertHeap(t, a)
+	if v, _ := a.Peek(); v != -5 {
+		t.Errorf("Peek = %d, want -5", v)
+	}
+}
+
+// TestMergeIncompatible tests that queues with different less functions
+// are rejected and left unchanged
+func TestMergeIncompatible(t *testing.T) {
+	a := NewInts([]int{1, 2})
+	b := New([]int{3, 4}, func(x, y int) bool { return x > y })
+
+	if err := a.Merge(b); err != ErrIncompatibleLess {
+		t.Errorf("Expected ErrIncompatibleLess, got %v", err)
+	}
+	if a.Size() != 2 || b.Size() != 2 {
+		t.Errorf("Sizes changed to %d and %d", a.Size(), b.Size())
+	}
+	if _, err := Meld(a, b); err != ErrIncompatibleLess {
+		t.Errorf("Meld: expected ErrIncompatibleLess, got %v", err)
+	}
+	if err := a.Merge(a); err != nil || a.Size() != 2 {
+		t.Errorf("Self merge = %v with size %d", err, a.Size())
+	}
+
+	// The same literal written twice is different code
+	c := New([]int{1}, func(x, y int) bool { return x < y })
+	d := New([]int{2}, func(x, y int) bool { return x < y })
+	if err := c.Merge(d); err != ErrIncompatibleLess {
+		t.Errorf("Separate literals: expected ErrIncompatibleLess, got %v", err)
+	}
+}
+
+// TestMergeSameLiteral pins that closures from one literal are accepted
+// whatever state they capture, as the Merge documentation warns
+func TestMergeSameLiteral(t *testing.T) {
+	byField := func(i int) func(x, y [2]int) bool {
+		return func(x, y [2]int) bool { return x[i] < y[i] }
+	}
+	a := New([][2]int{{1, 9}}, byField(0))
+	b := New([][2]int{{2, 8}}, byField(1))
+	if err := a.Merge(b); err != nil {
+		t.Errorf("Closures of one literal: expected acceptance, got %v", err)
+	}
+	if a.Size() != 2 {
+		t.Errorf("Size %d, want 2", a.Size())
+	}
+}
+
+// TestMeld tests combining several queues into a new one
+func TestMeld(t *testing.T) {
+	for _, hk := range allHeapKinds {
+		t.Run(hk.name, func(t *testing.T) {
+			var qs []*PQueue[string]
+			var want []string
+			for i := 0; i < 4; i++ {
+				q := NewStrings(nil, WithHeapKind(hk.kind))
+				for j := 0; j < 50; j++ {
+					s := string(rune('a' + rand.Intn(26)))
+					q.Push(s)
+					want = append(want, s)
+				}
+				qs = append(qs, q)
+			}
+			qs = append(qs, nil)
+
+			melded, err := Meld(qs...)
+			if err != nil {
+				t.Fatalf("Meld: %v", err)
+			}
+			if melded.GetHeapKind() != qs[0].GetHeapKind() {
+				t.Errorf("Expected backend %v, got %v", qs[0].GetHeapKind(), melded.GetHeapKind())
+			}
+			for _, q := range qs[:4] {
+				if !q.IsEmpty() {
+					t.Error("Expected input queue to be empty")
+				}
+			}
+
+			sort.Strings(want)
+			for i, w := range want {
+				if v, err := melded.Pop(); err != nil || v != w {
+					t.Fatalf("Pop %d = %q, %v, want %q", i, v, err, w)
+				}
+			}
+		})
+	}
+
+	if _, err := Meld[int](); err != ErrEmpty {
+		t.Errorf("Meld of nothing: expected ErrEmpty, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package pqueue
+
+import "math/bits"
+
+// MinMaxPQueue is a double-ended priority queue backed by a min-max heap.
+// Both the smallest and the largest element can be read in O(1) and
+// removed in O(log n).
+type MinMaxPQueue[T any] struct {
+	data []T
+	less func(T, T) bool
+}
+
+// NewMinMax creates a MinMaxPQueue with the given data and comparison
+// function. The data is copied and heapified in O(n).
+func NewMinMax[T any](data []T, less func(T, T) bool) *MinMaxPQueue[T] {
+	q := &MinMaxPQueue[T]{
+		data: make([]T, len(data)),
+		less: less,
+	}
+	copy(q.data, data)
+	for i := len(q.data)/2 - 1; i >= 0; i-- {
+		q.trickleDown(i)
+	}
+	return q
+}
+
+// NewMinMaxInts creates a new MinMaxPQueue for integers
+func NewMinMaxInts(data []int) *MinMaxPQueue[int] {
+	return NewMinMax(data, func(a, b int) bool { return a < b })
+}
+
+// NewMinMaxFloats creates a new MinMaxPQueue for floats
+func NewMinMaxFloats(data []float64) *MinMaxPQueue[float64] {
+	return NewMinMax(data, func(a, b float64) bool { return a < b })
+}
+
+// NewMinMaxStrings creates a new MinMaxPQueue for strings
+func NewMinMaxStrings(data []string) *MinMaxPQueue[string] {
+	return NewMinMax(data, func(a, b string) bool { return a < b })
+}
+
+// Size returns the number of elements in the queue
+func (q *MinMaxPQueue[T]) Size() int {
+	return len(q.data)
+}
+
+// IsEmpty returns true if the queue is empty
+func (q *MinMaxPQueue[T]) IsEmpty() bool {
+	return len(q.data) == 0
+}
+
+// Push adds an element to the queue in O(log n)
+func (q *MinMaxPQueue[T]) Push(item T) {
+	q.data = append(q.data, item)
+	q.bubbleUp(len(q.data) - 1)
+}
+
+// PeekMin returns the smallest element without removing it in O(1)
+func (q *MinMaxPQueue[T]) PeekMin() (T, error) {
+	var zero T
+	if len(q.data) == 0 {
+		return zero, ErrEmpty
+	}
+	return q.data[0], nil
+}
+
+// PeekMax returns the largest element without removing it in O(1)
+func (q *MinMaxPQueue[T]) PeekMax() (T, error) {
+	var zero T
+	if len(q.data) == 0 {
+		return zero, ErrEmpty
+	}
+	return q.data[q.maxIndex()], nil
+}
+
+// PopMin removes and returns the smallest element in O(log n)
+func (q *MinMaxPQueue[T]) PopMin() (T, error) {
+	var zero T
+	if len(q.data) == 0 {
+		return zero, ErrEmpty
+	}
+	return q.removeAt(0), nil
+}
+
+// PopMax removes and returns the largest element in O(log n)
+func (q *MinMaxPQueue[T]) PopMax() (T, error) {
+	var zero T
+	if len(q.data) == 0 {
+		return zero, ErrEmpty
+	}
+	return q.removeAt(q.maxIndex()), nil
+}
+
+// ToSlice returns a copy of the elements in heap order
+func (q *MinMaxPQueue[T]) ToSlice() []T {
+	result := make([]T, len(q.data))
+	copy(result, q.data)
+	return result
+}
+
+// maxIndex returns the index of the largest element: the root when it is
+// alone, otherwise the larger of its children on the first max level
+func (q *MinMaxPQueue[T]) maxIndex() int {
+	switch len(q.data) {
+	case 1:
+		return 0
+	case 2:
+		return 1
+	}
+	if q.less(q.data[1], q.data[2]) {
+		return 2
+	}
+	return 1
+}
+
+// removeAt removes the element at index i, which must be the root or one
+// of its children, by moving the last element into its place
+func (q *MinMaxPQueue[T]) removeAt(i int) T {
+	var zero T
+	last := len(q.data) - 1
+	item := q.data[i]
+	q.data[i] = q.data[last]
+	q.data[last] = zero // allow the element to be collected
+	q.data = q.data[:last]
+	if i < last {
+		q.trickleDown(i)
+	}
+	return item
+}
+
+// isMinLevel reports whether index i lies on a min level. The root is on
+// level 0 and levels alternate between min and max.
+func isMinLevel(i int) bool {
+	return bits.Len(uint(i+1))%2 == 1
+}
+
+// before reports whether the element at i belongs above the element at j
+// on a min level, or on a max level when max is set
+func (q *MinMaxPQueue[T]) before(i, j int, max bool) bool {
+	if max {
+		return q.less(q.data[j], q.data[i])
+	}
+	return q.less(q.data[i], q.data[j])
+}
+
+// bubbleUp restores the heap property after appending the element at i
+func (q *MinMaxPQueue[T]) bubbleUp(i int) {
+	if i == 0 {
+		return
+	}
+	max := !isMinLevel(i)
+	p := (i - 1) / 2
+	if q.before(p, i, max) {
+		// The element belongs on the opposite kind of level
+		q.data[i], q.data[p] = q.data[p], q.data[i]
+		q.bubbleUpGrandparents(p, !max)
+		return
+	}
+	q.bubbleUpGrandparents(i, max)
+}
+
+// bubbleUpGrandparents moves the element at i up through levels of its
+// own kind
+func (q *MinMaxPQueue[T]) bubbleUpGrandparents(i int, max bool) {
+	for i > 2 {
+		g := ((i-1)/2 - 1) / 2
+		if !q.before(i, g, max) {
+			return
+		}
+		q.data[i], q.data[g] = q.data[g], q.data[i]
+		i = g
+	}
+}
+
+// trickleDown moves the element at i down to restore the heap property
+func (q *MinMaxPQueue[T]) trickleDown(i int) {
+	max := !isMinLevel(i)
+	n := len(q.data)
+	for {
+		// Find the best of the children and grandchildren of i
+		m := -1
+		first := 2*i + 1
+		for c := first; c < first+2 && c < n; c++ {
+			if m < 0 || q.before(c, m, max) {
+				m = c
+			}
+			for g := 2*c + 1; g < 2*c+3 && g < n; g++ {
+				if q.before(g, m, max) {
+					m = g
+				}
+			}
+		}
+		if m < 0 || !q.before(m, i, max) {
+			return
+		}
+
+		q.data[i], q.data[m] = q.data[m], q.data[i]
+		if m <= first+1 {
+			return // swapping with a child finishes the trickle
+		}
+		if p := (m - 1) / 2; q.before(p, m, max) {
+			q.data[m], q.data[p] = q.data[p], q.data[m]
+		}
+		i = m
+	}
+}
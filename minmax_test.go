@@ -0,0 +1,111 @@
+package pqueue
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// assertMinMax checks every element against all of its ancestors
+func assertMinMax[T any](t *testing.T, q *MinMaxPQueue[T]) {
+	t.Helper()
+	for i := 1; i < len(q.data); i++ {
+		for a := (i - 1) / 2; ; a = (a - 1) / 2 {
+			if q.before(i, a, !isMinLevel(a)) {
+				t.Fatalf("Element %d is out of order with ancestor %d", i, a)
+			}
+			if a == 0 {
+				break
+			}
+		}
+	}
+}
+
+// TestMinMaxConstruction tests heapifying initial data
+func TestMinMaxConstruction(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 7, 100, 1000} {
+		data := make([]int, n)
+		for i := range data {
+			data[i] = rand.Intn(n + 1)
+		}
+		q := NewMinMaxInts(data)
+		assertMinMax(t, q)
+		if q.Size() != n {
+			t.Errorf("Expected size %d, got %d", n, q.Size())
+		}
+	}
+}
+
+// TestMinMaxPopBothEnds tests random operations against a sorted model
+func TestMinMaxPopBothEnds(t *testing.T) {
+	q := NewMinMaxInts(nil)
+	var model []int
+
+	for step := 0; step < 5000; step++ {
+		switch op := rand.Intn(4); {
+		case op < 2 || len(model) == 0:
+			v := rand.Intn(1000)
+			q.Push(v)
+			model = append(model, v)
+			sort.Ints(model)
+		case op == 2:
+			v, err := q.PopMin()
+			if err != nil || v != model[0] {
+				t.Fatalf("PopMin = %d, %v, want %d", v, err, model[0])
+			}
+			model = model[1:]
+		default:
+			v, err := q.PopMax()
+			if err != nil || v != model[len(model)-1] {
+				t.Fatalf("PopMax = %d, %v, want %d", v, err, model[len(model)-1])
+			}
+			model = model[:len(model)-1]
+		}
+
+		if len(model) > 0 {
+			lo, _ := q.PeekMin()
+			hi, _ := q.PeekMax()
+			if lo != model[0] || hi != model[len(model)-1] {
+				t.Fatalf("Peek = %d, %d, want %d, %d", lo, hi, model[0], model[len(model)-1])
+			}
+		}
+	}
+	assertMinMax(t, q)
+}
+
+// TestMinMaxEmpty tests errors on an empty queue
+func TestMinMaxEmpty(t *testing.T) {
+	q := NewMinMaxStrings(nil)
+	if _, err := q.PeekMin(); err != ErrEmpty {
+		t.Errorf("PeekMin: expected ErrEmpty, got %v", err)
+	}
+	if _, err := q.PeekMax(); err != ErrEmpty {
+		t.Errorf("PeekMax: expected ErrEmpty, got %v", err)
+	}
+	if _, err := q.PopMin(); err != ErrEmpty {
+		t.Errorf("PopMin: expected ErrEmpty, got %v", err)
+	}
+	if _, err := q.PopMax(); err != ErrEmpty {
+		t.Errorf("PopMax: expected ErrEmpty, got %v", err)
+	}
+}
+
+// TestMinMaxFloats tests draining from both ends alternately
+func TestMinMaxFloats(t *testing.T) {
+	q := NewMinMaxFloats([]float64{3.5, -1, 2.25, 8, 0, 5})
+	want := []float64{-1, 8, 0, 5, 2.25, 3.5}
+	for i, w := range want {
+		var v float64
+		if i%2 == 0 {
+			v, _ = q.PopMin()
+		} else {
+			v, _ = q.PopMax()
+		}
+		if v != w {
+			t.Errorf("Pop %d = %v, want %v", i, v, w)
+		}
+	}
+	if !q.IsEmpty() {
+		t.Error("Expected queue to be empty")
+	}
+}
@@ -0,0 +1,185 @@
+package pqueue
+
+import (
+	"math"
+	"reflect"
+	"unsafe"
+)
+
+// endOfKey is the symbol reported past the end of a key. It sorts before
+// every byte and rune, so a key sorts before its own extensions.
+const endOfKey = math.MinInt
+
+// msdInsertionCutoff is the partition size below which multikey quicksort
+// finishes with insertion sort
+const msdInsertionCutoff = 16
+
+// msdPrefixThreshold is the sampled mean common prefix length, in symbols,
+// above which AutoStrategy prefers MSD radix sort for sequence data
+const msdPrefixThreshold = 8
+
+// msdSamples is the number of element pairs sampled to estimate the mean
+// common prefix length
+const msdSamples = 32
+
+// sequenceSorter sorts queue data of strings, byte slices or rune slices
+// symbol by symbol rather than through less
+type sequenceSorter interface {
+	msdSort()
+	commonPrefix() int
+}
+
+// byteSeqs implements sequenceSorter over strings or byte slices
+type byteSeqs[S ~string | ~[]byte] []S
+
+func (s byteSeqs[S]) msdSort() {
+	multikeySort(s, 0, byteAt[S])
+}
+
+func (s byteSeqs[S]) commonPrefix() int {
+	return sampledPrefix(s, byteAt[S])
+}
+
+// runeSeqs implements sequenceSorter over rune slices
+type runeSeqs[S ~[]rune] []S
+
+func (s runeSeqs[S]) msdSort() {
+	multikeySort(s, 0, runeAt[S])
+}
+
+func (s runeSeqs[S]) commonPrefix() int {
+	return sampledPrefix(s, runeAt[S])
+}
+
+// byteAt returns byte d of x, or endOfKey past its end
+func byteAt[S ~string | ~[]byte](x S, d int) int {
+	if d < len(x) {
+		return int(x[d])
+	}
+	return endOfKey
+}
+
+// runeAt returns rune d of x, or endOfKey past its end
+func runeAt[S ~[]rune](x S, d int) int {
+	if d < len(x) {
+		return int(x[d])
+	}
+	return endOfKey
+}
+
+// sequences returns a sorter over the queue data when T is a string, byte
+// slice or rune slice kind and the queue is in its natural lexicographic
+// order. The data is reinterpreted in place as the matching built-in type.
+func (pq *PQueue[T]) sequences() (sequenceSorter, bool) {
+	if !pq.natural {
+		return nil, false
+	}
+
+	p, n := unsafe.Pointer(unsafe.SliceData(pq.data)), pq.size
+	t := reflect.TypeFor[T]()
+	switch {
+	case t.Kind() == reflect.String:
+		return byteSeqs[string](unsafe.Slice((*string)(p), n)), true
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return byteSeqs[[]byte](unsafe.Slice((*[]byte)(p), n)), true
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Int32:
+		return runeSeqs[[]rune](unsafe.Slice((*[]rune)(p), n)), true
+	}
+	return nil, false
+}
+
+// msdRadixSort sorts string, byte slice and rune slice data with multikey
+// quicksort, falling back to pdqsort for anything else
+func (pq *PQueue[T]) msdRadixSort() {
+	s, ok := pq.sequences()
+	if !ok {
+		pq.pdqsort()
+		return
+	}
+	s.msdSort()
+}
+
+// multikeySort sorts s, whose elements share their first depth symbols,
+// with Bentley and Sedgewick's multikey quicksort: a three-way partition on
+// the symbol at depth, recursing into the equal part one symbol deeper
+func multikeySort[S any](s []S, depth int, at func(S, int) int) {
+	for len(s) > msdInsertionCutoff {
+		// Median of three symbols as the pivot
+		a, b, c := at(s[0], depth), at(s[len(s)/2], depth), at(s[len(s)-1], depth)
+		if a > b {
+			a, b = b, a
+		}
+		if b > c {
+			b = c
+			if a > b {
+				b = a
+			}
+		}
+		pivot := b
+
+		lt, i, gt := 0, 0, len(s)
+		for i < gt {
+			switch sym := at(s[i], depth); {
+			case sym < pivot:
+				s[lt], s[i] = s[i], s[lt]
+				lt++
+				i++
+			case sym > pivot:
+				gt--
+				s[i], s[gt] = s[gt], s[i]
+			default:
+				i++
+			}
+		}
+
+		multikeySort(s[:lt], depth, at)
+		multikeySort(s[gt:], depth, at)
+		if pivot == endOfKey {
+			return // the equal part holds identical, fully consumed keys
+		}
+		s, depth = s[lt:gt], depth+1
+	}
+
+	// Insertion sort comparing from depth onwards
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && symbolsLess(s[j], s[j-1], depth, at); j-- {
+			s[j], s[j-1] = s[j-1], s[j]
+		}
+	}
+}
+
+// symbolsLess compares a and b symbol by symbol starting at depth
+func symbolsLess[S any](a, b S, depth int, at func(S, int) int) bool {
+	for d := depth; ; d++ {
+		x, y := at(a, d), at(b, d)
+		if x != y {
+			return x < y
+		}
+		if x == endOfKey {
+			return false
+		}
+	}
+}
+
+// sampledPrefix estimates the mean common prefix length of the elements of
+// s from evenly spaced pairs half the slice apart
+func sampledPrefix[S any](s []S, at func(S, int) int) int {
+	n := len(s)
+	if n < 2 {
+		return 0
+	}
+	samples := min(msdSamples, n/2)
+	total := 0
+	for k := 0; k < samples; k++ {
+		i := k * n / samples
+		a, b := s[i], s[(i+n/2)%n]
+		for d := 0; ; d++ {
+			x := at(a, d)
+			if x != at(b, d) || x == endOfKey {
+				break
+			}
+			total++
+		}
+	}
+	return total / samples
+}
@@ -0,0 +1,109 @@
+package pqueue
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// generateLogKeys returns strings sharing a long prefix, with duplicates,
+// empty strings and keys that are prefixes of others
+func generateLogKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		switch rand.Intn(20) {
+		case 0:
+			keys[i] = ""
+		case 1:
+			keys[i] = "2024-06-01T12:"
+		default:
+			keys[i] = fmt.Sprintf("2024-06-01T12:%02d:%02d.%03d host-%d", rand.Intn(60), rand.Intn(60), rand.Intn(1000), rand.Intn(8))
+		}
+	}
+	return keys
+}
+
+// TestMSDRadixStrings tests multikey quicksort on strings
+func TestMSDRadixStrings(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 100, 5000} {
+		data := generateLogKeys(n)
+		want := slices.Clone(data)
+		sort.Strings(want)
+
+		pq := NewStrings(data)
+		pq.SortWithStrategy(MSDRadixStrategy)
+		if got := pq.ToSlice(); !slices.Equal(got, want) {
+			t.Errorf("Size %d: result not sorted", n)
+		}
+		assertHeap(t, pq)
+	}
+
+	// All equal keys
+	same := slices.Repeat([]string{"abc"}, 100)
+	SortSliceWithStrategy(same, func(a, b string) bool { return a < b }, MSDRadixStrategy)
+	if !slices.Equal(same, slices.Repeat([]string{"abc"}, 100)) {
+		t.Error("Equal keys were changed")
+	}
+}
+
+// TestMSDRadixBytesAndRunes tests multikey quicksort on byte and rune slices
+func TestMSDRadixBytesAndRunes(t *testing.T) {
+	keys := generateLogKeys(2000)
+
+	byteData := make([][]byte, len(keys))
+	runeData := make([][]rune, len(keys))
+	for i, k := range keys {
+		byteData[i] = []byte(k)
+		runeData[i] = []rune(k)
+		if i%50 == 0 {
+			runeData[i] = append(runeData[i], -1) // invalid runes still order by value
+		}
+	}
+
+	bq := NewBytes(byteData)
+	bq.SortWithStrategy(MSDRadixStrategy)
+	got := bq.ToSlice()
+	for i := 1; i < len(got); i++ {
+		if string(got[i-1]) > string(got[i]) {
+			t.Fatalf("Byte slices not sorted at %d: %q > %q", i, got[i-1], got[i])
+		}
+	}
+
+	rq := NewRunes(runeData)
+	rq.SortWithStrategy(MSDRadixStrategy)
+	runes := rq.ToSlice()
+	for i := 1; i < len(runes); i++ {
+		if slices.Compare(runes[i-1], runes[i]) > 0 {
+			t.Fatalf("Rune slices not sorted at %d: %v > %v", i, runes[i-1], runes[i])
+		}
+	}
+}
+
+// TestMSDRadixSelection tests when AutoStrategy picks MSD radix sort
+func TestMSDRadixSelection(t *testing.T) {
+	if s := NewStrings(generateLogKeys(2000)).chooseOptimalStrategy(); s != MSDRadixStrategy {
+		t.Errorf("Long shared prefixes: got strategy %v, want MSDRadixStrategy", s)
+	}
+
+	short := make([]string, 2000)
+	for i := range short {
+		short[i] = fmt.Sprint(rand.Int())
+	}
+	if s := NewStrings(short).chooseOptimalStrategy(); s == MSDRadixStrategy {
+		t.Error("Short random keys should not use MSD radix sort")
+	}
+
+	// A custom order must not be replaced by byte order
+	data := generateLogKeys(2000)
+	pq := New(data, func(a, b string) bool { return strings.ToUpper(a) > strings.ToUpper(b) })
+	pq.SortWithStrategy(MSDRadixStrategy)
+	got := pq.ToSlice()
+	for i := 1; i < len(got); i++ {
+		if strings.ToUpper(got[i-1]) < strings.ToUpper(got[i]) {
+			t.Fatalf("Custom order ignored at %d", i)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package pqueue
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync"
+)
+
+// popSamples is the number of two-shard samples TryPop takes before it
+// falls back to scanning every shard
+const popSamples = 4
+
+// MultiQueue is a relaxed concurrent priority queue for high-contention
+// workloads. Elements are spread across independently locked PQueue shards;
+// TryPop samples two shards and pops the smaller of their heads. Pops are
+// therefore only approximately ordered: the rank error grows linearly with
+// the shard count, which WithShards configures, but only in expectation.
+// A single pop may stray much further.
+type MultiQueue[T any] struct {
+	shards []mqShard[T]
+	less   func(T, T) bool
+}
+
+// mqShard is a locked PQueue padded to its own cache line
+type mqShard[T any] struct {
+	mu    sync.Mutex
+	queue *PQueue[T]
+	_     [48]byte
+}
+
+// NewMultiQueue creates an empty MultiQueue ordered by less. Unless
+// WithShards is given it uses two shards per GOMAXPROCS.
+func NewMultiQueue[T any](less func(T, T) bool, opts ...Option) *MultiQueue[T] {
+	o := newOptions(opts)
+	shards := o.shards
+	if shards <= 0 {
+		shards = 2 * runtime.GOMAXPROCS(0)
+	}
+
+	q := &MultiQueue[T]{
+		shards: make([]mqShard[T], shards),
+		less:   less,
+	}
+	for i := range q.shards {
+		q.shards[i].queue = New(nil, less, opts...)
+	}
+	return q
+}
+
+// Size returns the number of elements across all shards. Under concurrent
+// use the result is a snapshot that may already be stale.
+func (q *MultiQueue[T]) Size() int {
+	total := 0
+	for i := range q.shards {
+		s := &q.shards[i]
+		s.mu.Lock()
+		total += s.queue.Size()
+		s.mu.Unlock()
+	}
+	return total
+}
+
+// IsEmpty returns true if every shard is empty
+func (q *MultiQueue[T]) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Push adds an element to a randomly chosen shard, preferring shards that
+// are not currently locked
+func (q *MultiQueue[T]) Push(item T) {
+	for attempt := 0; ; attempt++ {
+		s := &q.shards[rand.IntN(len(q.shards))]
+		if attempt < len(q.shards) {
+			if !s.mu.TryLock() {
+				continue
+			}
+		} else {
+			s.mu.Lock()
+		}
+		s.queue.Push(item)
+		s.mu.Unlock()
+		return
+	}
+}
+
+// TryPop removes and returns a small element without blocking: the smaller
+// head of two randomly sampled shards. If sampling keeps failing it pops
+// the head of the first non-empty shard it finds, whatever its rank. It
+// returns ErrEmpty only if every shard was found empty.
+func (q *MultiQueue[T]) TryPop() (T, error) {
+	n := len(q.shards)
+	for attempt := 0; attempt < popSamples; attempt++ {
+		i, j := rand.IntN(n), rand.IntN(n)
+		if item, ok := q.popBetter(i, j); ok {
+			return item, nil
+		}
+	}
+
+	// Sampling kept hitting empty or busy shards: scan them all in turn
+	start := rand.IntN(n)
+	for k := 0; k < n; k++ {
+		s := &q.shards[(start+k)%n]
+		s.mu.Lock()
+		item, err := s.queue.Pop()
+		s.mu.Unlock()
+		if err == nil {
+			return item, nil
+		}
+	}
+
+	var zero T
+	return zero, ErrEmpty
+}
+
+// popBetter pops the smaller head of shards i and j. It gives up, reporting
+// false, if either shard is busy or both are empty.
+func (q *MultiQueue[T]) popBetter(i, j int) (T, bool) {
+	var zero T
+	a := &q.shards[i]
+	if !a.mu.TryLock() {
+		return zero, false
+	}
+	defer a.mu.Unlock()
+
+	best := a
+	if j != i {
+		b := &q.shards[j]
+		if !b.mu.TryLock() {
+			return zero, false
+		}
+		defer b.mu.Unlock()
+
+		headA, errA := a.queue.Peek()
+		headB, errB := b.queue.Peek()
+		if errA != nil || (errB == nil && q.less(headB, headA)) {
+			best = b
+		}
+	}
+
+	item, err := best.queue.Pop()
+	return item, err == nil
+}
@@ -0,0 +1,123 @@
+package pqueue
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// TestMultiQueueExactWithOneShard tests that a single shard pops in order
+func TestMultiQueueExactWithOneShard(t *testing.T) {
+	q := NewMultiQueue(func(a, b int) bool { return a < b }, WithShards(1))
+	data := rand.Perm(500)
+	for _, v := range data {
+		q.Push(v)
+	}
+
+	for want := 0; want < len(data); want++ {
+		got, err := q.TryPop()
+		if err != nil || got != want {
+			t.Fatalf("TryPop = %d, %v, want %d", got, err, want)
+		}
+	}
+	if _, err := q.TryPop(); err != ErrEmpty {
+		t.Errorf("Expected ErrEmpty, got %v", err)
+	}
+}
+
+// TestMultiQueueRankError tests that pops stay close to the true minimum
+func TestMultiQueueRankError(t *testing.T) {
+	const shards, n = 8, 20000
+	q := NewMultiQueue(func(a, b int) bool { return a < b }, WithShards(shards))
+	for _, v := range rand.Perm(n) {
+		q.Push(v)
+	}
+
+	// Every value is unique, so the rank of a popped value is the number of
+	// smaller values still queued
+	remaining := make([]bool, n)
+	for i := range remaining {
+		remaining[i] = true
+	}
+	smallest := 0
+	totalRank := 0
+	for i := 0; i < n; i++ {
+		v, err := q.TryPop()
+		if err != nil {
+			t.Fatalf("TryPop: %v", err)
+		}
+		if !remaining[v] {
+			t.Fatalf("Value %d popped twice", v)
+		}
+		remaining[v] = false
+		for smallest < n && !remaining[smallest] {
+			smallest++
+		}
+		for k := smallest; k < v; k++ {
+			if remaining[k] {
+				totalRank++
+			}
+		}
+	}
+
+	// The mean rank error is linear in the shard count; allow a wide margin
+	if avg := float64(totalRank) / n; avg > 4*shards {
+		t.Errorf("Average rank error %.2f exceeds %d", avg, 4*shards)
+	}
+}
+
+// TestMultiQueueConcurrent tests concurrent pushes and pops lose nothing
+func TestMultiQueueConcurrent(t *testing.T) {
+	q := NewMultiQueue(func(a, b int) bool { return a < b })
+
+	const workers, perWorker = 16, 1000
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				q.Push(w*perWorker + i)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if q.Size() != workers*perWorker {
+		t.Fatalf("Expected size %d, got %d", workers*perWorker, q.Size())
+	}
+
+	results := make([][]int, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for {
+				v, err := q.TryPop()
+				if err != nil {
+					return
+				}
+				results[w] = append(results[w], v)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	var popped []int
+	for _, r := range results {
+		popped = append(popped, r...)
+	}
+	sort.Ints(popped)
+	if len(popped) != workers*perWorker {
+		t.Fatalf("Popped %d elements, want %d", len(popped), workers*perWorker)
+	}
+	for i, v := range popped {
+		if v != i {
+			t.Fatalf("Element %d missing or duplicated", i)
+		}
+	}
+	if !q.IsEmpty() {
+		t.Error("Expected queue to be empty")
+	}
+}
@@ -0,0 +1,116 @@
+package pqueue
+
+// Option configures a queue at construction time
+type Option func(*options)
+
+// options holds the settings applied by Option functions
+type options struct {
+	heapKind    HeapKind
+	shards      int
+	clock       Clock
+	nanOrder    NaNOrder
+	workers     int
+	parallelMin int
+	memoryLimit int
+	tempDir     string
+	thresholds  Thresholds
+	selector    *AdaptiveSelector
+}
+
+// newOptions applies opts on top of the defaults
+func newOptions(opts []Option) options {
+	o := options{
+		heapKind: BinaryHeap,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithHeapKind selects the heap implementation backing Push, Pop and Peek
+func WithHeapKind(kind HeapKind) Option {
+	return func(o *options) {
+		o.heapKind = kind
+	}
+}
+
+// WithShards sets the number of shards a MultiQueue spreads elements
+// across. More shards mean less contention but looser pops: the rank of a
+// popped element averages somewhat below the shard count, with no bound on
+// any single pop. One shard pops exactly. Other queue types ignore this
+// option.
+func WithShards(n int) Option {
+	return func(o *options) {
+		o.shards = n
+	}
+}
+
+// WithClock sets the time source used by DelayQueue. Other queue types
+// ignore this option.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithNaNOrder sets where NewFloats and NewOrdered queues of floating-point
+// elements order NaNs, both when popping and when sorting. Queues cannot
+// report errors on Push, so they treat NaNReject like NaNFirst.
+func WithNaNOrder(order NaNOrder) Option {
+	return func(o *options) {
+		o.nanOrder = order
+	}
+}
+
+// WithWorkers sets how many goroutines ParallelMergeStrategy sorts with.
+// Zero or less uses runtime.GOMAXPROCS, and 1 keeps AutoStrategy
+// sequential.
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+// WithParallelThreshold sets the size from which AutoStrategy sorts in
+// parallel. Zero or less uses the default of 65536 elements.
+func WithParallelThreshold(n int) Option {
+	return func(o *options) {
+		o.parallelMin = n
+	}
+}
+
+// WithThresholds tunes the rules AutoStrategy selects a sort by and the
+// sample size Profile uses. Zero fields keep their defaults.
+func WithThresholds(t Thresholds) Option {
+	return func(o *options) {
+		o.thresholds = t
+	}
+}
+
+// WithAdaptiveSelector lets the selector learn from the timings of the
+// queue's AutoStrategy sorts and pick the fastest strategy it has measured
+// for each. Sorts of inputs small enough for insertion sort are left to the
+// rules.
+func WithAdaptiveSelector(a *AdaptiveSelector) Option {
+	return func(o *options) {
+		o.selector = a
+	}
+}
+
+// WithMemoryLimit sets how many bytes of elements ExternalSorter buffers
+// before spilling a sorted run to disk. Other queue types ignore this
+// option.
+func WithMemoryLimit(bytes int) Option {
+	return func(o *options) {
+		o.memoryLimit = bytes
+	}
+}
+
+// WithTempDir sets the directory ExternalSorter writes its runs to. The
+// default is os.TempDir. Other queue types ignore this option.
+func WithTempDir(dir string) Option {
+	return func(o *options) {
+		o.tempDir = dir
+	}
+}
@@ -0,0 +1,143 @@
+package pqueue
+
+// pairingHeap is a pairing heap: O(1) insert and amortized O(log n)
+// extractMin using the standard two-pass pairing
+type pairingHeap[T any] struct {
+	root *heapNode[T]
+	less func(T, T) bool
+}
+
+// insert makes n the new root unless the current root is strictly smaller
+func (h *pairingHeap[T]) insert(n *heapNode[T]) {
+	n.child, n.left, n.right = nil, nil, nil
+	h.root = h.link(n, h.root)
+}
+
+func (h *pairingHeap[T]) min() *heapNode[T] {
+	return h.root
+}
+
+func (h *pairingHeap[T]) extractMin() *heapNode[T] {
+	root := h.root
+	h.root = h.mergePairs(root.child)
+	root.child = nil
+	return root
+}
+
+func (h *pairingHeap[T]) decreaseKey(n *heapNode[T]) {
+	if n == h.root {
+		return
+	}
+	h.cut(n)
+	h.root = h.link(h.root, n)
+}
+
+func (h *pairingHeap[T]) remove(n *heapNode[T]) {
+	if n == h.root {
+		h.extractMin()
+		return
+	}
+	h.cut(n)
+	h.root = h.link(h.root, h.mergePairs(n.child))
+	n.child = nil
+}
+
+func (h *pairingHeap[T]) each(fn func(n *heapNode[T]) bool) {
+	walkNodes(h.root, fn)
+}
+
+func (h *pairingHeap[T]) meld(other nodeHeap[T]) {
+	o := other.(*pairingHeap[T])
+	h.root = h.link(h.root, o.root)
+	o.root = nil
+}
+
+// link makes the larger of two detached roots the first child of the other
+func (h *pairingHeap[T]) link(a, b *heapNode[T]) *heapNode[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if h.less(b.value, a.value) {
+		a, b = b, a
+	}
+
+	b.left = a
+	b.right = a.child
+	if a.child != nil {
+		a.child.left = b
+	}
+	a.child = b
+	return a
+}
+
+// cut detaches the subtree rooted at n from its parent and siblings
+func (h *pairingHeap[T]) cut(n *heapNode[T]) {
+	if n.left.child == n {
+		n.left.child = n.right
+	} else {
+		n.left.right = n.right
+	}
+	if n.right != nil {
+		n.right.left = n.left
+	}
+	n.left, n.right = nil, nil
+}
+
+// mergePairs combines a sibling list into a single tree, linking pairs
+// left to right and then folding the results right to left
+func (h *pairingHeap[T]) mergePairs(first *heapNode[T]) *heapNode[T] {
+	// First pass: link siblings in pairs, stacking the results through right
+	var stack *heapNode[T]
+	for first != nil {
+		a, b := first, first.right
+		first = nil
+		if b != nil {
+			first = b.right
+			b.left, b.right = nil, nil
+		}
+		a.left, a.right = nil, nil
+
+		merged := h.link(a, b)
+		merged.right = stack
+		stack = merged
+	}
+
+	// Second pass: fold the stacked pairs from the rightmost one
+	var root *heapNode[T]
+	for stack != nil {
+		next := stack.right
+		stack.right = nil
+		root = h.link(root, stack)
+		stack = next
+	}
+	return root
+}
+
+// walkNodes visits every node reachable from start through child and right
+// links until fn returns false. Circular sibling lists are visited once.
+func walkNodes[T any](start *heapNode[T], fn func(n *heapNode[T]) bool) {
+	if start == nil {
+		return
+	}
+	stack := []*heapNode[T]{start}
+	for len(stack) > 0 {
+		first := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for n := first; n != nil; {
+			if !fn(n) {
+				return
+			}
+			if n.child != nil {
+				stack = append(stack, n.child)
+			}
+			n = n.right
+			if n == first {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package pqueue
+
+import (
+	"runtime"
+	"sync"
+)
+
+// defaultParallelThreshold is the size from which AutoStrategy sorts in
+// parallel unless WithParallelThreshold says otherwise
+const defaultParallelThreshold = 1 << 16
+
+// parallelMinChunk is the smallest chunk worth handing to a worker
+const parallelMinChunk = 1 << 12
+
+// workerCount returns the number of goroutines parallel sorts may use
+func (o options) workerCount() int {
+	if o.workers > 0 {
+		return o.workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// parallelThreshold returns the size from which AutoStrategy sorts in
+// parallel
+func (o options) parallelThreshold() int {
+	if o.parallelMin > 0 {
+		return o.parallelMin
+	}
+	return defaultParallelThreshold
+}
+
+// parallelMergeSort splits the data into one contiguous chunk per worker,
+// timsorts the chunks concurrently and merges adjacent runs pairwise, each
+// merge split between the workers at evenly spaced output positions. Every
+// step is stable, so the sort is too. less is called from several
+// goroutines at once.
+func (pq *PQueue[T]) parallelMergeSort() {
+	n := pq.size
+	workers := min(pq.opts.workerCount(), n/parallelMinChunk)
+	if workers < 2 {
+		pq.timsort()
+		return
+	}
+	data := pq.data[:n]
+
+	bounds := make([]int, workers+1)
+	for i := range bounds {
+		bounds[i] = i * n / workers
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		chunk := data[bounds[w]:bounds[w+1]]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sliceQueue(chunk, pq.less).timsort()
+		}()
+	}
+	wg.Wait()
+
+	// Merge runs pairwise, alternating between data and the buffer
+	src, dst := data, make([]T, n)
+	for len(bounds) > 2 {
+		runs := len(bounds) - 1
+		parts := max(1, workers/(runs/2))
+		next := []int{0}
+		for r := 0; r+1 < runs; r += 2 {
+			lo, mid, hi := bounds[r], bounds[r+1], bounds[r+2]
+			mergeParallel(&wg, dst[lo:hi], src[lo:mid], src[mid:hi], pq.less, parts)
+			next = append(next, hi)
+		}
+		if runs%2 == 1 {
+			lo := bounds[runs-1]
+			copy(dst[lo:], src[lo:])
+			next = append(next, n)
+		}
+		wg.Wait()
+		src, dst = dst, src
+		bounds = next
+	}
+
+	if &src[0] != &data[0] {
+		copy(data, src)
+	}
+}
+
+// mergeParallel merges the sorted slices a and b into dst, starting one
+// goroutine on wg for each of parts equal pieces of the output
+func mergeParallel[T any](wg *sync.WaitGroup, dst, a, b []T, less func(a, b T) bool, parts int) {
+	n := len(dst)
+	prevI, prevK := 0, 0
+	for p := 1; p <= parts; p++ {
+		k := p * n / parts
+		i := coRank(k, a, b, less)
+		out, left, right := dst[prevK:k], a[prevI:i], b[prevK-prevI:k-i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mergeInto(out, left, right, less)
+		}()
+		prevI, prevK = i, k
+	}
+}
+
+// coRank returns how many elements of a are among the first k elements of
+// the stable merge of a and b, by binary search
+func coRank[T any](k int, a, b []T, less func(a, b T) bool) int {
+	lo, hi := max(0, k-len(b)), min(k, len(a))
+	for lo < hi {
+		i := int(uint(lo+hi) >> 1)
+		// a[i] precedes b[j-1] in the merge, so more of a is in the prefix
+		if j := k - i; j > 0 && !less(b[j-1], a[i]) {
+			lo = i + 1
+		} else {
+			hi = i
+		}
+	}
+	return lo
+}
+
+// mergeInto merges the sorted slices a and b into dst, taking from a on
+// ties
+func mergeInto[T any](dst, a, b []T, less func(a, b T) bool) {
+	i, j, k := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		if less(b[j], a[i]) {
+			dst[k] = b[j]
+			j++
+		} else {
+			dst[k] = a[i]
+			i++
+		}
+		k++
+	}
+	k += copy(dst[k:], a[i:])
+	copy(dst[k:], b[j:])
+}
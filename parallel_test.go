@@ -0,0 +1,85 @@
+package pqueue
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// TestParallelMergeSort tests that the parallel sort is a stable sort for
+// worker counts that do and do not divide the input
+func TestParallelMergeSort(t *testing.T) {
+	less := func(a, b keyed) bool { return a.key < b.key }
+	for _, workers := range []int{1, 2, 3, 4, 7, 32} {
+		for _, n := range []int{100, 8191, 50000} {
+			for _, shape := range []string{"random", "descending", "runs"} {
+				data := keyedInput(n, 50, shape)
+				want := slices.Clone(data)
+				slices.SortStableFunc(want, func(a, b keyed) int { return a.key - b.key })
+
+				pq := sliceQueue(slices.Clone(data), less)
+				pq.opts.workers = workers
+				pq.SortWithStrategy(ParallelMergeStrategy)
+				if !slices.Equal(pq.data, want) {
+					t.Errorf("%d workers, size %d, %s: result is not a stable sort", workers, n, shape)
+				}
+			}
+		}
+	}
+}
+
+// TestMergeParallel tests splitting merges at more points than elements
+func TestMergeParallel(t *testing.T) {
+	less := func(a, b keyed) bool { return a.key < b.key }
+	for _, parts := range []int{1, 2, 5, 40} {
+		for _, sizes := range [][2]int{{0, 0}, {0, 7}, {7, 0}, {1, 1}, {13, 29}, {500, 3}} {
+			a := keyedInput(sizes[0], 10, "ascending")
+			b := keyedInput(sizes[1], 10, "ascending")
+			for i := range b {
+				b[i].index += len(a)
+			}
+			want := slices.Concat(a, b)
+			slices.SortStableFunc(want, func(x, y keyed) int { return x.key - y.key })
+
+			got := make([]keyed, len(want))
+			var wg sync.WaitGroup
+			mergeParallel(&wg, got, a, b, less, parts)
+			wg.Wait()
+			if !slices.Equal(got, want) {
+				t.Errorf("Sizes %v in %d parts: got %v, want %v", sizes, parts, got, want)
+			}
+		}
+	}
+}
+
+// TestParallelSelection tests the options controlling when AutoStrategy
+// sorts in parallel
+func TestParallelSelection(t *testing.T) {
+	type point struct{ x, y int }
+	less := func(a, b point) bool { return a.x < b.x }
+	points := make([]point, 10000)
+	for i := range points {
+		points[i] = point{rand.Intn(1000), i}
+	}
+
+	if s := New(points, less, WithWorkers(4), WithParallelThreshold(5000)).chooseOptimalStrategy(); s != ParallelMergeStrategy {
+		t.Errorf("Above the threshold: got strategy %v, want ParallelMergeStrategy", s)
+	}
+	if s := New(points, less, WithWorkers(4)).chooseOptimalStrategy(); s == ParallelMergeStrategy {
+		t.Error("Below the default threshold the sort should stay sequential")
+	}
+	if s := New(points, less, WithWorkers(1), WithParallelThreshold(5000)).chooseOptimalStrategy(); s == ParallelMergeStrategy {
+		t.Error("A single worker should keep the sort sequential")
+	}
+
+	pq := New(points, less, WithWorkers(4), WithParallelThreshold(5000))
+	pq.Sort()
+	got := pq.ToSlice()
+	for i := 1; i < len(got); i++ {
+		if got[i-1].x > got[i].x {
+			t.Fatalf("Result not sorted at %d", i)
+		}
+	}
+	assertHeap(t, pq)
+}
@@ -0,0 +1,246 @@
+package pqueue
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// partialHeapRatio is the smallest n/k for which selecting the k smallest
+// of n elements uses a heap of size k rather than quickselect. Below it the
+// O(n log k) heap scan costs more than quickselect's linear passes.
+const partialHeapRatio = 64
+
+// selectMethod is an algorithm for finding the k smallest elements
+type selectMethod int
+
+const (
+	heapSelectMethod  selectMethod = iota // scan with a max-heap of the k smallest so far
+	quickSelectMethod                     // introselect, then sort the prefix
+)
+
+// chooseSelectMethod picks how to find the k smallest of n elements
+func chooseSelectMethod(n, k int) selectMethod {
+	if k*partialHeapRatio <= n {
+		return heapSelectMethod
+	}
+	return quickSelectMethod
+}
+
+// partialSorter is implemented by every PQueue instantiation, so the
+// algorithms can run on queue data or on a permutation of it
+type partialSorter interface {
+	partialSort(k int)
+	nthElement(k int)
+}
+
+// PartialSort arranges an array-backed queue so that ToSlice begins with
+// its k smallest elements in priority order, followed by the rest in no
+// particular order. It does less work than Sort when k is small; k beyond
+// the queue size sorts everything. The heap is rebuilt afterwards and
+// handles stay valid.
+func (pq *PQueue[T]) PartialSort(k int) {
+	k = min(k, pq.size)
+	if k <= 0 {
+		return
+	}
+	pq.rearrange(k-1, func(s partialSorter) { s.partialSort(k) })
+}
+
+// NthElement returns the element that index k would hold if the queue were
+// sorted. Array-backed queues are left arranged so that ToSlice holds it at
+// index k with no greater element before it and no smaller element after
+// it. The heap is rebuilt afterwards and handles stay valid. It returns
+// ErrOutOfRange if k is not a valid index.
+func (pq *PQueue[T]) NthElement(k int) (T, error) {
+	if k < 0 || k >= pq.size {
+		var zero T
+		return zero, ErrOutOfRange
+	}
+	return pq.rearrange(k, func(s partialSorter) { s.nthElement(k) }), nil
+}
+
+// SelectK returns the k smallest elements in priority order, or all of them
+// if the queue holds fewer, without modifying the queue. It walks the heap
+// from its root with Sorted, so on array backends it costs O(k log k)
+// regardless of the queue size; on pairing and Fibonacci heaps it can cost
+// O(n log n) even for small k.
+func (pq *PQueue[T]) SelectK(k int) []T {
+	k = min(k, pq.size)
+	if k <= 0 {
+		return nil
+	}
+	out := make([]T, 0, k)
+	for v := range pq.Sorted() {
+		out = append(out, v)
+		if len(out) == k {
+			break
+		}
+	}
+	return out
+}
+
+// PartialSortSlice rearranges s in place so that it begins with its k
+// smallest elements in the order of less, followed by the rest in no
+// particular order
+func PartialSortSlice[T any](s []T, less func(T, T) bool, k int) {
+	k = min(k, len(s))
+	if k > 0 {
+		sliceQueue(s, less).partialSort(k)
+	}
+}
+
+// NthElementSlice rearranges s in place so that s[k] holds the element it
+// would hold if s were sorted, with no greater element before it and no
+// smaller element after it, and returns that element. It returns
+// ErrOutOfRange if k is not a valid index.
+func NthElementSlice[T any](s []T, less func(T, T) bool, k int) (T, error) {
+	if k < 0 || k >= len(s) {
+		var zero T
+		return zero, ErrOutOfRange
+	}
+	sliceQueue(s, less).nthElement(k)
+	return s[k], nil
+}
+
+// SelectKSlice returns the k smallest elements of s in the order of less,
+// or all of them sorted if s holds fewer, leaving s unchanged. For small k
+// it keeps only k elements in memory.
+func SelectKSlice[T any](s []T, less func(T, T) bool, k int) []T {
+	k = min(k, len(s))
+	if k <= 0 {
+		return nil
+	}
+	if chooseSelectMethod(len(s), k) == heapSelectMethod {
+		pq := sliceQueue(slices.Clone(s[:k]), less)
+		pq.heapSelect(k, s[k:], false)
+		return pq.data
+	}
+
+	pq := sliceQueue(slices.Clone(s), less)
+	pq.partialSort(k)
+	return slices.Clip(pq.data[:k])
+}
+
+// rearrange runs a partial sort over the queue data, through a permutation
+// when handles must follow their elements, and restores the heap. It
+// returns the element the sort left at index at.
+func (pq *PQueue[T]) rearrange(at int, apply func(s partialSorter)) T {
+	pq.mods++
+	nodes := pq.flatten()
+
+	if pq.refs != nil {
+		pq.permuteTracked(func(sorter *PQueue[int]) { apply(sorter) })
+	} else {
+		apply(pq)
+	}
+	result := pq.data[at]
+
+	// The prefix holds no element greater than the rest and children follow
+	// their parents in data, so heapifying moves nothing across index at
+	if nodes {
+		pq.useHeap(pq.heapKind)
+	} else {
+		pq.buildHeap()
+	}
+	return result
+}
+
+// partialSort moves the k smallest elements, sorted, to the front of data
+func (pq *PQueue[T]) partialSort(k int) {
+	n := pq.size
+	if chooseSelectMethod(n, k) == heapSelectMethod {
+		pq.heapSelect(k, pq.data[k:n], true)
+		return
+	}
+
+	if k == n {
+		pq.pdqsortFirst(n)
+		return
+	}
+	pq.nthElement(k - 1)
+	pq.pdqsortFirst(k - 1)
+}
+
+// nthElement places the element of rank k at index k, partitioning the
+// rest around it
+func (pq *PQueue[T]) nthElement(k int) {
+	pq.introselect(0, pq.size, k, 2*bits.Len(uint(pq.size)))
+}
+
+// heapSelect arranges the k smallest of data[:k] and rest in data[:k], in
+// ascending order. data[:k] is made a max-heap and each element of rest
+// smaller than its top replaces the top. With swap the replaced elements
+// are moved into rest, otherwise rest is left unchanged.
+func (pq *PQueue[T]) heapSelect(k int, rest []T, swap bool) {
+	for i := k/2 - 1; i >= 0; i-- {
+		pq.heapify(0, k, i)
+	}
+	for i := range rest {
+		if pq.less(rest[i], pq.data[0]) {
+			if swap {
+				rest[i], pq.data[0] = pq.data[0], rest[i]
+			} else {
+				pq.data[0] = rest[i]
+			}
+			pq.heapify(0, k, 0)
+		}
+	}
+	for i := k - 1; i > 0; i-- {
+		pq.swap(0, i)
+		pq.heapify(0, i, 0)
+	}
+}
+
+// introselect places the element of rank k within data[lo:hi] at index k
+// by repeatedly partitioning with the same pivots and partition as
+// pdqsort. Once budget unbalanced partitions are spent, pivots come from
+// median of medians, which bounds the remaining work to linear time.
+func (pq *PQueue[T]) introselect(lo, hi, k, budget int) {
+	for hi-lo > pdqInsertionCutoff {
+		var pivot int
+		if budget > 0 {
+			pivot, _ = pq.choosePivot(lo, hi)
+		} else {
+			pivot = pq.medianOfMedians(lo, hi)
+		}
+
+		mid, _ := pq.partitionPdq(lo, hi, pivot)
+		if min(mid-lo, hi-mid) < (hi-lo)/8 {
+			budget--
+		}
+
+		switch {
+		case k == mid:
+			return
+		case k < mid:
+			hi = mid
+		case mid-lo < (hi-lo)/8:
+			// A tiny left side suggests many elements equal the pivot: skip
+			// past all of them at once
+			equal := pq.partitionEqual(mid, hi, mid)
+			if k < equal {
+				return
+			}
+			lo = equal
+		default:
+			lo = mid + 1
+		}
+	}
+	pq.insertionSortRange(lo, hi-1)
+}
+
+// medianOfMedians returns the index of an element of data[lo:hi] whose rank
+// is between 30% and 70% of the range: the median of the medians of groups
+// of five, which are gathered at the front of the range
+func (pq *PQueue[T]) medianOfMedians(lo, hi int) int {
+	groups := 0
+	for i := lo; i+5 <= hi; i += 5 {
+		pq.insertionSortRange(i, i+4)
+		pq.swap(lo+groups, i+2)
+		groups++
+	}
+
+	mid := lo + groups/2
+	pq.introselect(lo, lo+groups, mid, 0)
+	return mid
+}
@@ -0,0 +1,203 @@
+package pqueue
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// checkNth fails the test unless data[k] is want with no greater element
+// before it and no smaller element after it
+func checkNth(t *testing.T, data []int, k, want int) {
+	t.Helper()
+	if data[k] != want {
+		t.Fatalf("data[%d] = %d, want %d", k, data[k], want)
+	}
+	for i, v := range data {
+		if (i < k && v > want) || (i > k && v < want) {
+			t.Fatalf("data[%d] = %d is on the wrong side of data[%d] = %d", i, v, k, want)
+		}
+	}
+}
+
+// TestPartialSortSlice tests both select methods on patterned input
+func TestPartialSortSlice(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	for _, n := range []int{1, 13, 100, 1000, 20000} {
+		for name, data := range patternInputs(n) {
+			want := slices.Clone(data)
+			slices.Sort(want)
+
+			for _, k := range []int{1, 2, n / 100, n / 2, n - 1, n, n + 1} {
+				if k <= 0 {
+					continue
+				}
+				got := slices.Clone(data)
+				PartialSortSlice(got, less, k)
+				k = min(k, n)
+				if !slices.Equal(got[:k], want[:k]) {
+					t.Fatalf("%s, size %d, k %d: prefix not the k smallest in order", name, n, k)
+				}
+				slices.Sort(got)
+				if !slices.Equal(got, want) {
+					t.Fatalf("%s, size %d, k %d: elements lost", name, n, k)
+				}
+
+				if sel := SelectKSlice(data, less, k); !slices.Equal(sel, want[:k]) {
+					t.Fatalf("%s, size %d, k %d: SelectKSlice got %v", name, n, k, sel)
+				}
+			}
+		}
+	}
+}
+
+// TestNthElementSlice tests selection on patterned input
+func TestNthElementSlice(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	for _, n := range []int{1, 13, 100, 1000, 20000} {
+		for name, data := range patternInputs(n) {
+			want := slices.Clone(data)
+			slices.Sort(want)
+
+			for _, k := range []int{0, 1, n / 3, n / 2, n - 1} {
+				if k >= n {
+					continue
+				}
+				got := slices.Clone(data)
+				v, err := NthElementSlice(got, less, k)
+				if err != nil || v != want[k] {
+					t.Fatalf("%s, size %d, k %d: got %d, %v, want %d", name, n, k, v, err, want[k])
+				}
+				checkNth(t, got, k, want[k])
+			}
+		}
+	}
+
+	for _, k := range []int{-1, 3} {
+		if _, err := NthElementSlice([]int{1, 2, 3}, less, k); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("k %d: expected ErrOutOfRange, got %v", k, err)
+		}
+	}
+}
+
+// TestMedianOfMedians tests introselect with no budget, which takes every
+// pivot from median of medians, and the rank guarantee of its pivots
+func TestMedianOfMedians(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	for name, data := range patternInputs(5000) {
+		want := slices.Clone(data)
+		slices.Sort(want)
+
+		got := slices.Clone(data)
+		pq := sliceQueue(got, less)
+		pivot := pq.medianOfMedians(0, len(got))
+		below := 0
+		for _, v := range want {
+			if v < got[pivot] {
+				below++
+			}
+		}
+		if below > len(got)*7/10 {
+			t.Errorf("%s: pivot has %d smaller elements of %d", name, below, len(got))
+		}
+
+		for _, k := range []int{0, 2500, 4999} {
+			got := slices.Clone(data)
+			sliceQueue(got, less).introselect(0, len(got), k, 0)
+			checkNth(t, got, k, want[k])
+		}
+	}
+}
+
+// TestChooseSelectMethod tests the k/n heuristic
+func TestChooseSelectMethod(t *testing.T) {
+	tests := []struct {
+		n, k int
+		want selectMethod
+	}{
+		{100000, 10, heapSelectMethod},
+		{6400, 100, heapSelectMethod},
+		{6399, 100, quickSelectMethod},
+		{1000, 500, quickSelectMethod},
+		{10, 10, quickSelectMethod},
+	}
+	for _, tt := range tests {
+		if got := chooseSelectMethod(tt.n, tt.k); got != tt.want {
+			t.Errorf("chooseSelectMethod(%d, %d) = %v, want %v", tt.n, tt.k, got, tt.want)
+		}
+	}
+}
+
+// TestSelectKSliceUnchanged tests that SelectKSlice leaves its input alone
+func TestSelectKSliceUnchanged(t *testing.T) {
+	data := generateRandomInts(10000)
+	orig := slices.Clone(data)
+	for _, k := range []int{10, 5000} {
+		SelectKSlice(data, func(a, b int) bool { return a < b }, k)
+		if !slices.Equal(data, orig) {
+			t.Fatalf("k %d: input modified", k)
+		}
+	}
+}
+
+// TestPartialQueue tests the queue methods on every heap backend, with
+// handles that must follow their elements
+func TestPartialQueue(t *testing.T) {
+	for _, hk := range allHeapKinds {
+		t.Run(hk.name, func(t *testing.T) {
+			for _, k := range []int{1, 7, 500, 999} {
+				pq := NewInts(nil, WithHeapKind(hk.kind))
+				handles := map[*Handle[int]]int{}
+				for i := 0; i < 1000; i++ {
+					v := rand.Intn(300)
+					handles[pq.PushHandle(v)] = v
+				}
+				want := pq.ToSlice()
+				slices.Sort(want)
+
+				if got := pq.SelectK(k); !slices.Equal(got, want[:k]) {
+					t.Fatalf("k %d: SelectK got %v", k, got)
+				}
+
+				v, err := pq.NthElement(k)
+				if err != nil || v != want[k] {
+					t.Fatalf("k %d: NthElement = %d, %v, want %d", k, v, err, want[k])
+				}
+				if pq.nodes == nil {
+					checkNth(t, pq.ToSlice(), k, want[k])
+				}
+				assertHeap(t, pq)
+
+				pq.PartialSort(k)
+				if pq.nodes == nil && !slices.Equal(pq.ToSlice()[:k], want[:k]) {
+					t.Fatalf("k %d: PartialSort prefix not the k smallest in order", k)
+				}
+				assertHeap(t, pq)
+
+				for h, want := range handles {
+					if got, err := pq.Value(h); err != nil || got != want {
+						t.Fatalf("k %d: Value = %d, %v, want %d", k, got, err, want)
+					}
+				}
+				for i, want := range want {
+					if got, _ := pq.Pop(); got != want {
+						t.Fatalf("k %d: Pop %d = %d, want %d", k, i, got, want)
+					}
+				}
+			}
+		})
+	}
+
+	pq := NewInts([]int{3, 1, 2})
+	if _, err := pq.NthElement(3); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange, got %v", err)
+	}
+	if got := pq.SelectK(10); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("SelectK beyond size got %v", got)
+	}
+	pq.PartialSort(0)
+	if got := pq.SelectK(0); got != nil {
+		t.Errorf("SelectK(0) got %v", got)
+	}
+}
@@ -0,0 +1,391 @@
+package pqueue
+
+import (
+	"math/bits"
+	"unsafe"
+)
+
+const (
+	// pdqInsertionCutoff is the range size sorted with insertion sort
+	pdqInsertionCutoff = 12
+
+	// pdqNintherThreshold is the range size from which the pivot is the
+	// median of three medians of three
+	pdqNintherThreshold = 50
+
+	// pdqBlockSize is the number of elements the branchless partition
+	// classifies before swapping
+	pdqBlockSize = 64
+
+	// pdqSmallKey is the largest element size, in bytes, partitioned with
+	// the branchless block partition
+	pdqSmallKey = 16
+)
+
+// sortedHint records what choosePivot learned about the order of a range
+type sortedHint int
+
+const (
+	unknownHint sortedHint = iota
+	increasingHint
+	decreasingHint
+)
+
+// pdqsort performs pattern-defeating quicksort: introsort with pivots
+// chosen by median of three or ninther, detection of sorted and reversed
+// runs, pattern breaking after unbalanced partitions and a heapsort
+// fallback that bounds the worst case to O(n log n)
+func (pq *PQueue[T]) pdqsort() {
+	pq.pdqsortFirst(pq.size)
+}
+
+// pdqsortFirst sorts data[:n] with pdqsort
+func (pq *PQueue[T]) pdqsortFirst(n int) {
+	var zero T
+	blocks := unsafe.Sizeof(zero) <= pdqSmallKey
+	pq.pdqsortRange(0, n, bits.Len(uint(n)), blocks)
+}
+
+// pdqsortRange sorts data[a:b]. limit is the number of unbalanced
+// partitions allowed before switching to heapsort.
+func (pq *PQueue[T]) pdqsortRange(a, b, limit int, blocks bool) {
+	wasBalanced := true
+	wasPartitioned := true
+
+	for {
+		length := b - a
+		if length <= pdqInsertionCutoff {
+			pq.insertionSortRange(a, b-1)
+			return
+		}
+
+		// Too many bad pivots: fall back to heapsort
+		if limit == 0 {
+			pq.heapSortRange(a, b-1)
+			return
+		}
+
+		// The last partition was unbalanced: shuffle some elements to break
+		// the pattern that caused it
+		if !wasBalanced {
+			pq.breakPatterns(a, b)
+			limit--
+		}
+
+		pivot, hint := pq.choosePivot(a, b)
+		if hint == decreasingHint {
+			pq.reverse(a, b-1)
+			pivot = (b - 1) - (pivot - a)
+			hint = increasingHint
+		}
+
+		// The range looks sorted: try to finish it with a few insertions
+		if wasBalanced && wasPartitioned && hint == increasingHint {
+			if pq.partialInsertionSort(a, b) {
+				return
+			}
+		}
+
+		// The pivot equals the element before the range, which no element
+		// of the range is smaller than: move the equal elements aside
+		if a > 0 && !pq.less(pq.data[a-1], pq.data[pivot]) {
+			a = pq.partitionEqual(a, b, pivot)
+			continue
+		}
+
+		var mid int
+		if blocks {
+			mid, wasPartitioned = pq.partitionBlocks(a, b, pivot)
+		} else {
+			mid, wasPartitioned = pq.partitionPdq(a, b, pivot)
+		}
+
+		// Recurse into the shorter side and loop on the longer one
+		left, right := mid-a, b-mid
+		balanceThreshold := length / 8
+		if left < right {
+			wasBalanced = left >= balanceThreshold
+			pq.pdqsortRange(a, mid, limit, blocks)
+			a = mid + 1
+		} else {
+			wasBalanced = right >= balanceThreshold
+			pq.pdqsortRange(mid+1, b, limit, blocks)
+			b = mid
+		}
+	}
+}
+
+// partitionPdq partitions data[a:b] around the element at pivot so that
+// smaller elements precede it and the rest follow. It returns the pivot's
+// new index and whether the range was already partitioned.
+func (pq *PQueue[T]) partitionPdq(a, b, pivot int) (int, bool) {
+	d := pq.data
+	pq.swap(a, pivot)
+	i, j := a+1, b-1
+
+	for i <= j && pq.less(d[i], d[a]) {
+		i++
+	}
+	for i <= j && !pq.less(d[j], d[a]) {
+		j--
+	}
+	if i > j {
+		pq.swap(j, a)
+		return j, true
+	}
+	pq.swap(i, j)
+	i++
+	j--
+
+	for {
+		for i <= j && pq.less(d[i], d[a]) {
+			i++
+		}
+		for i <= j && !pq.less(d[j], d[a]) {
+			j--
+		}
+		if i > j {
+			break
+		}
+		pq.swap(i, j)
+		i++
+		j--
+	}
+	pq.swap(j, a)
+	return j, false
+}
+
+// partitionBlocks is partitionPdq using BlockQuicksort's branchless
+// scheme: each side classifies a block of elements into an offset buffer,
+// turning comparison results into arithmetic instead of branches, and the
+// misplaced elements are then swapped pairwise
+func (pq *PQueue[T]) partitionBlocks(a, b, pivot int) (int, bool) {
+	d := pq.data
+	pq.swap(a, pivot)
+	p := d[a]
+
+	first, last := a+1, b
+	for first < last && pq.less(d[first], p) {
+		first++
+	}
+	for first < last && !pq.less(d[last-1], p) {
+		last--
+	}
+	partitioned := first >= last
+
+	if !partitioned {
+		last--
+		pq.swap(first, last)
+		first++
+
+		// Elements before first are smaller than p and elements from last
+		// on are not. offsetsL holds positions after baseL of elements that
+		// belong on the right, offsetsR positions before baseR of elements
+		// that belong on the left.
+		var offsetsL, offsetsR [pdqBlockSize]uint8
+		baseL, baseR := first, last
+		numL, numR, startL, startR := 0, 0, 0, 0
+
+		for first < last {
+			unknown := last - first
+			leftSplit, rightSplit := 0, 0
+			if numL == 0 {
+				leftSplit = unknown
+				if numR == 0 {
+					leftSplit = unknown / 2
+				}
+			}
+			if numR == 0 {
+				rightSplit = unknown - leftSplit
+			}
+
+			for i := 0; i < min(leftSplit, pdqBlockSize); i++ {
+				offsetsL[numL] = uint8(i)
+				numL += boolToInt(!pq.less(d[first], p))
+				first++
+			}
+			for i := 0; i < min(rightSplit, pdqBlockSize); {
+				i++
+				last--
+				offsetsR[numR] = uint8(i)
+				numR += boolToInt(pq.less(d[last], p))
+			}
+
+			num := min(numL, numR)
+			for k := 0; k < num; k++ {
+				l := baseL + int(offsetsL[startL+k])
+				r := baseR - int(offsetsR[startR+k])
+				pq.swap(l, r)
+			}
+			numL -= num
+			numR -= num
+			startL += num
+			startR += num
+			if numL == 0 {
+				startL, baseL = 0, first
+			}
+			if numR == 0 {
+				startR, baseR = 0, last
+			}
+		}
+
+		// One side may still hold misplaced elements: move them to the
+		// boundary
+		for numL > 0 {
+			numL--
+			last--
+			l := baseL + int(offsetsL[startL+numL])
+			pq.swap(l, last)
+			first = last
+		}
+		for numR > 0 {
+			numR--
+			r := baseR - int(offsetsR[startR+numR])
+			pq.swap(r, first)
+			first++
+		}
+	}
+
+	mid := first - 1
+	pq.swap(a, mid)
+	return mid, partitioned
+}
+
+// boolToInt converts without a branch
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// partitionEqual moves the elements of data[a:b] equal to the element at
+// pivot to the front and returns the index of the first greater element
+func (pq *PQueue[T]) partitionEqual(a, b, pivot int) int {
+	d := pq.data
+	pq.swap(a, pivot)
+	i, j := a+1, b-1
+
+	for {
+		for i <= j && !pq.less(d[a], d[i]) {
+			i++
+		}
+		for i <= j && pq.less(d[a], d[j]) {
+			j--
+		}
+		if i > j {
+			break
+		}
+		pq.swap(i, j)
+		i++
+		j--
+	}
+	return i
+}
+
+// partialInsertionSort sorts data[a:b] if it is at most a few misplaced
+// elements away from sorted, reporting whether it succeeded
+func (pq *PQueue[T]) partialInsertionSort(a, b int) bool {
+	const (
+		maxSteps         = 5  // misplaced elements to fix at most
+		shortestShifting = 50 // shorter ranges are not worth fixing
+	)
+	d := pq.data
+	i := a + 1
+
+	for step := 0; step < maxSteps; step++ {
+		for i < b && !pq.less(d[i], d[i-1]) {
+			i++
+		}
+		if i == b {
+			return true
+		}
+		if b-a < shortestShifting {
+			return false
+		}
+		pq.swap(i, i-1)
+
+		// Shift the smaller element to the left and the larger one right
+		for j := i - 1; j > a && pq.less(d[j], d[j-1]); j-- {
+			pq.swap(j, j-1)
+		}
+		for j := i + 1; j < b && pq.less(d[j], d[j-1]); j++ {
+			pq.swap(j, j-1)
+		}
+	}
+	return false
+}
+
+// breakPatterns swaps three elements near the middle of data[a:b] with
+// pseudo-randomly chosen ones
+func (pq *PQueue[T]) breakPatterns(a, b int) {
+	length := b - a
+	if length < 8 {
+		return
+	}
+
+	random := uint64(length)
+	modulus := uint64(1) << bits.Len(uint(length))
+	idx := a + length/4*2 - 1
+	for i := 0; i < 3; i++ {
+		// xorshift64
+		random ^= random << 13
+		random ^= random >> 7
+		random ^= random << 17
+
+		other := int(random & (modulus - 1))
+		if other >= length {
+			other -= length
+		}
+		pq.swap(idx-1+i, a+other)
+	}
+}
+
+// choosePivot picks a pivot index for data[a:b] by median of three, or by
+// ninther for long ranges. When no candidate comparison needed a swap the
+// range is likely increasing; when all did it is likely decreasing.
+func (pq *PQueue[T]) choosePivot(a, b int) (int, sortedHint) {
+	const maxSwaps = 4 * 3
+
+	length := b - a
+	swaps := 0
+	i := a + length/4*1
+	j := a + length/4*2
+	k := a + length/4*3
+
+	if length >= 8 {
+		if length >= pdqNintherThreshold {
+			i = pq.median(i-1, i, i+1, &swaps)
+			j = pq.median(j-1, j, j+1, &swaps)
+			k = pq.median(k-1, k, k+1, &swaps)
+		}
+		j = pq.median(i, j, k, &swaps)
+	}
+
+	switch swaps {
+	case 0:
+		return j, increasingHint
+	case maxSwaps:
+		return j, decreasingHint
+	default:
+		return j, unknownHint
+	}
+}
+
+// median returns the index of the median of three elements, counting the
+// out-of-order pairs it encountered in swaps
+func (pq *PQueue[T]) median(a, b, c int, swaps *int) int {
+	a, b = pq.order2(a, b, swaps)
+	b, c = pq.order2(b, c, swaps)
+	_, b = pq.order2(a, b, swaps)
+	return b
+}
+
+// order2 returns two indices ordered by their elements
+func (pq *PQueue[T]) order2(a, b int, swaps *int) (int, int) {
+	if pq.less(pq.data[b], pq.data[a]) {
+		*swaps++
+		return b, a
+	}
+	return a, b
+}
@@ -0,0 +1,124 @@
+package pqueue
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// patternInputs returns inputs that defeat naive pivot choices
+func patternInputs(n int) map[string][]int {
+	inputs := map[string][]int{}
+	add := func(name string, f func(i int) int) {
+		data := make([]int, n)
+		for i := range data {
+			data[i] = f(i)
+		}
+		inputs[name] = data
+	}
+
+	add("sorted", func(i int) int { return i })
+	add("reversed", func(i int) int { return n - i })
+	add("equal", func(i int) int { return 7 })
+	add("organ pipe", func(i int) int { return min(i, n-i) })
+	add("sawtooth", func(i int) int { return i % 64 })
+	add("few values", func(i int) int { return rand.Intn(4) })
+	add("random", func(i int) int { return rand.Int() })
+	add("sorted tail swap", func(i int) int {
+		if i == n-1 {
+			return -1
+		}
+		return i
+	})
+	return inputs
+}
+
+// TestPdqPatterns tests pdqsort on patterned input with both partition
+// schemes
+func TestPdqPatterns(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	for _, n := range []int{0, 1, 2, 13, 50, 100, 1000, 20000} {
+		for name, data := range patternInputs(n) {
+			want := slices.Clone(data)
+			slices.Sort(want)
+
+			for _, blocks := range []bool{true, false} {
+				got := slices.Clone(data)
+				pq := sliceQueue(got, less)
+				pq.pdqsortRange(0, len(got), 64, blocks)
+				if !slices.Equal(got, want) {
+					t.Errorf("%s, size %d, blocks %v: result not sorted", name, n, blocks)
+				}
+			}
+		}
+	}
+}
+
+// TestPdqLargeElements tests the branching partition chosen for element
+// types too large for block partitioning
+func TestPdqLargeElements(t *testing.T) {
+	type record struct {
+		key     int
+		payload [4]int
+	}
+
+	data := make([]record, 5000)
+	for i := range data {
+		data[i] = record{key: rand.Intn(500), payload: [4]int{i}}
+	}
+	pq := New(data, func(a, b record) bool { return a.key < b.key })
+	pq.SortWithStrategy(PdqStrategy)
+	got := pq.ToSlice()
+	for i := 1; i < len(got); i++ {
+		if got[i-1].key > got[i].key {
+			t.Fatalf("Records not sorted at %d", i)
+		}
+	}
+	assertHeap(t, pq)
+}
+
+// TestPdqComparisons tests that patterned input stays close to n log n
+// comparisons, including when the heapsort fallback is forced
+func TestPdqComparisons(t *testing.T) {
+	const n = 1 << 14
+	for name, data := range patternInputs(n) {
+		for _, limit := range []int{64, 0} {
+			comparisons := 0
+			got := slices.Clone(data)
+			pq := sliceQueue(got, func(a, b int) bool {
+				comparisons++
+				return a < b
+			})
+			pq.pdqsortRange(0, n, limit, true)
+
+			if !slices.IsSorted(got) {
+				t.Errorf("%s with limit %d: result not sorted", name, limit)
+			}
+			if comparisons > 4*n*14 {
+				t.Errorf("%s with limit %d: %d comparisons", name, limit, comparisons)
+			}
+		}
+	}
+}
+
+// TestPdqIsDefault tests that AutoStrategy picks pdqsort for general data
+func TestPdqIsDefault(t *testing.T) {
+	type point struct{ x, y int }
+
+	points := make([]point, 2000)
+	for i := range points {
+		points[i] = point{rand.Intn(100), rand.Intn(100)}
+	}
+	pq := New(points, func(a, b point) bool { return a.x < b.x })
+	if s := pq.chooseOptimalStrategy(); s != PdqStrategy {
+		t.Errorf("Structs: got strategy %v, want PdqStrategy", s)
+	}
+
+	descending := make([]int, 2000)
+	for i := range descending {
+		descending[i] = rand.Int()
+	}
+	if s := New(descending, func(a, b int) bool { return a > b }).chooseOptimalStrategy(); s != PdqStrategy {
+		t.Errorf("Custom order: got strategy %v, want PdqStrategy", s)
+	}
+}
@@ -3,16 +3,59 @@
 package pqueue
 
 import (
-	"fmt"
+	"cmp"
+	"errors"
 	"reflect"
 )
 
-// PQueue represents an intelligent priority queue with adaptive sorting
+var (
+	// ErrEmpty is returned when popping or peeking an empty queue
+	ErrEmpty = errors.New("queue is empty")
+
+	// ErrInvalidHandle is returned for handles whose element has already
+	// been popped or removed
+	ErrInvalidHandle = errors.New("invalid or stale handle")
+
+	// ErrClosed is returned by concurrent queues once they have been closed
+	ErrClosed = errors.New("queue is closed")
+
+	// ErrNotReady is returned by DelayQueue when no element is ready yet
+	ErrNotReady = errors.New("no element is ready")
+
+	// ErrIncompatibleLess is returned when merging queues whose less
+	// functions differ
+	ErrIncompatibleLess = errors.New("queues use different less functions")
+
+	// ErrNaN is returned by SortFloats with NaNReject when the input
+	// contains a NaN
+	ErrNaN = errors.New("NaN cannot be ordered")
+
+	// ErrOutOfRange is returned by NthElement for an index outside the
+	// queue
+	ErrOutOfRange = errors.New("index out of range")
+
+	// ErrInvalidTable is returned when importing an AdaptiveSelector table
+	// that names unknown data types or strategies
+	ErrInvalidTable = errors.New("invalid adaptive selector table")
+)
+
+// PQueue represents an intelligent priority queue with adaptive sorting.
+// Elements are kept in a min-heap ordered by less; the heap implementation
+// is selected with WithHeapKind and defaults to an implicit binary heap.
 type PQueue[T any] struct {
 	data     []T
 	less     func(T, T) bool
 	dataType DataType
 	size     int
+	opts     options
+	heapKind HeapKind     // backend currently in use, never AutoHeap
+	arity    int          // children per node of the implicit heap in data
+	refs     []*Handle[T] // handles parallel to data, nil until a handle is issued
+	nodes    nodeHeap[T]  // pairing or Fibonacci backend, nil when data holds the heap
+	ops      opCounter    // operation mix observed for AutoHeap
+	mods     uint64       // modification count, checked by All and Sorted
+	natural  bool         // less is the natural order of T, enabling key-based sorts
+	swaps    int          // elements exchanged by sorts, read by SortWithReport
 }
 
 // DataType represents the type of data being sorted
@@ -45,62 +88,110 @@ const (
 	IntrosortStrategy
 	MergeStrategy
 	QuickStrategy
+	MSDRadixStrategy      // multikey quicksort for strings, byte slices and rune slices
+	PdqStrategy           // pattern-defeating quicksort
+	ParallelMergeStrategy // stable merge sort across goroutines
+)
+
+// HeapKind represents the heap implementation backing the queue
+type HeapKind int
+
+const (
+	BinaryHeap HeapKind = iota
+	QuaternaryHeap
+	PairingHeap
+	FibonacciHeap
+	AutoHeap
+)
+
+// NaNOrder selects where floating-point NaNs sort relative to numbers
+type NaNOrder int
+
+const (
+	NaNFirst  NaNOrder = iota // NaNs before all numbers, as by cmp.Less
+	NaNLast                   // NaNs after all numbers
+	NaNReject                 // NaNs are an error where one can be reported
 )
 
-// New creates a new PQueue with the given data and comparison function
-func New[T any](data []T, less func(T, T) bool) *PQueue[T] {
+// New creates a new PQueue with the given data and comparison function.
+// The data is copied and heapified in O(n).
+func New[T any](data []T, less func(T, T) bool, opts ...Option) *PQueue[T] {
 	pq := &PQueue[T]{
 		data: make([]T, len(data)),
 		less: less,
 		size: len(data),
+		opts: newOptions(opts),
 	}
 	copy(pq.data, data)
 	pq.dataType = inferDataType(data)
+
+	kind := pq.opts.heapKind
+	if kind == AutoHeap {
+		kind = BinaryHeap // start simple until the operation mix is known
+	}
+	pq.useHeap(kind)
+	return pq
+}
+
+// NewOrdered creates a new PQueue for any ordered type in ascending order.
+// Knowing the order lets key-based strategies such as radix sort apply.
+// NaNs are ordered first unless WithNaNOrder(NaNLast) is given.
+func NewOrdered[T cmp.Ordered](data []T, opts ...Option) *PQueue[T] {
+	less := cmp.Less[T]
+	if newOptions(opts).nanOrder == NaNLast {
+		less = nanLastLess[T]
+	}
+	pq := New(data, less, opts...)
+	pq.natural = true
 	return pq
 }
 
 // NewInts creates a new PQueue for integers
-func NewInts(data []int) *PQueue[int] {
-	return New(data, func(a, b int) bool { return a < b })
+func NewInts(data []int, opts ...Option) *PQueue[int] {
+	return NewOrdered(data, opts...)
 }
 
 // NewFloats creates a new PQueue for floats
-func NewFloats(data []float64) *PQueue[float64] {
-	return New(data, func(a, b float64) bool { return a < b })
+func NewFloats(data []float64, opts ...Option) *PQueue[float64] {
+	return NewOrdered(data, opts...)
 }
 
 // NewStrings creates a new PQueue for strings
-func NewStrings(data []string) *PQueue[string] {
-	return New(data, func(a, b string) bool { return a < b })
+func NewStrings(data []string, opts ...Option) *PQueue[string] {
+	return NewOrdered(data, opts...)
 }
 
 // NewBytes creates a new PQueue for byte slices
-func NewBytes(data [][]byte) *PQueue[[]byte] {
-	return New(data, func(a, b []byte) bool {
+func NewBytes(data [][]byte, opts ...Option) *PQueue[[]byte] {
+	pq := New(data, func(a, b []byte) bool {
 		for i := 0; i < len(a) && i < len(b); i++ {
 			if a[i] != b[i] {
 				return a[i] < b[i]
 			}
 		}
 		return len(a) < len(b)
-	})
+	}, opts...)
+	pq.natural = true
+	return pq
 }
 
 // NewRunes creates a new PQueue for rune slices
-func NewRunes(data [][]rune) *PQueue[[]rune] {
-	return New(data, func(a, b []rune) bool {
+func NewRunes(data [][]rune, opts ...Option) *PQueue[[]rune] {
+	pq := New(data, func(a, b []rune) bool {
 		for i := 0; i < len(a) && i < len(b); i++ {
 			if a[i] != b[i] {
 				return a[i] < b[i]
 			}
 		}
 		return len(a) < len(b)
-	})
+	}, opts...)
+	pq.natural = true
+	return pq
 }
 
 // NewComparable creates a new PQueue for any comparable type
-func NewComparable[T comparable](data []T, less func(T, T) bool) *PQueue[T] {
-	return New(data, less)
+func NewComparable[T comparable](data []T, less func(T, T) bool, opts ...Option) *PQueue[T] {
+	return New(data, less, opts...)
 }
 
 // Comparable interface for types that can be compared
@@ -109,10 +200,10 @@ type Comparable interface {
 }
 
 // NewWithComparable creates a PQueue for types that implement Comparable
-func NewWithComparable[T Comparable](data []T) *PQueue[T] {
+func NewWithComparable[T Comparable](data []T, opts ...Option) *PQueue[T] {
 	return New(data, func(a, b T) bool {
 		return a.CompareTo(b) < 0
-	})
+	}, opts...)
 }
 
 // Size returns the number of elements in the queue
@@ -125,8 +216,22 @@ func (pq *PQueue[T]) IsEmpty() bool {
 	return pq.size == 0
 }
 
-// Push adds an element to the queue
+// Push adds an element to the queue in O(log n), or O(1) amortized for
+// the pairing and Fibonacci backends
 func (pq *PQueue[T]) Push(item T) {
+	pq.push(item, nil)
+}
+
+// push adds an element, optionally tracked by a handle
+func (pq *PQueue[T]) push(item T, h *Handle[T]) {
+	pq.mods++
+	pq.record(opPush)
+	if pq.nodes != nil {
+		pq.nodes.insert(newNode(item, h))
+		pq.size++
+		return
+	}
+
 	if pq.size >= len(pq.data) {
 		// Grow the slice
 		newSize := len(pq.data) * 2
@@ -136,49 +241,47 @@ func (pq *PQueue[T]) Push(item T) {
 		newData := make([]T, newSize)
 		copy(newData, pq.data[:pq.size])
 		pq.data = newData
+		if pq.refs != nil {
+			newRefs := make([]*Handle[T], newSize)
+			copy(newRefs, pq.refs[:pq.size])
+			pq.refs = newRefs
+		}
 	}
 	pq.data[pq.size] = item
+	if pq.refs != nil {
+		pq.setRef(pq.size, h)
+	}
 	pq.size++
+	pq.siftUp(pq.size - 1)
 }
 
-// Pop removes and returns the smallest element
+// Pop removes and returns the smallest element in O(log n)
 func (pq *PQueue[T]) Pop() (T, error) {
 	var zero T
 	if pq.size == 0 {
-		return zero, fmt.Errorf("queue is empty")
+		return zero, ErrEmpty
 	}
+	pq.mods++
+	pq.record(opPop)
 
-	// Find minimum element
-	minIdx := 0
-	for i := 1; i < pq.size; i++ {
-		if pq.less(pq.data[i], pq.data[minIdx]) {
-			minIdx = i
-		}
+	if pq.nodes != nil {
+		pq.size--
+		return releaseNode(pq.nodes.extractMin()), nil
 	}
-
-	result := pq.data[minIdx]
-	// Move last element to the position of removed element
-	pq.data[minIdx] = pq.data[pq.size-1]
-	pq.size--
-
-	return result, nil
+	return pq.removeAt(0), nil
 }
 
-// Peek returns the smallest element without removing it
+// Peek returns the smallest element without removing it in O(1)
 func (pq *PQueue[T]) Peek() (T, error) {
 	var zero T
 	if pq.size == 0 {
-		return zero, fmt.Errorf("queue is empty")
+		return zero, ErrEmpty
 	}
 
-	minIdx := 0
-	for i := 1; i < pq.size; i++ {
-		if pq.less(pq.data[i], pq.data[minIdx]) {
-			minIdx = i
-		}
+	if pq.nodes != nil {
+		return pq.nodes.min().value, nil
 	}
-
-	return pq.data[minIdx], nil
+	return pq.data[0], nil
 }
 
 // Sort sorts the queue using the optimal algorithm based on data characteristics
@@ -186,15 +289,31 @@ func (pq *PQueue[T]) Sort() {
 	pq.SortWithStrategy(AutoStrategy)
 }
 
-// SortWithStrategy sorts using a specific strategy
+// SortWithStrategy sorts using a specific strategy. Data sorted in
+// ascending order is a valid min-heap, so the queue stays usable afterwards.
+// Node-based backends are flattened for sorting and rebuilt from the result.
 func (pq *PQueue[T]) SortWithStrategy(strategy SortStrategy) {
 	if pq.size <= 1 {
 		return
 	}
+	pq.mods++
+	if pq.flatten() {
+		defer pq.useHeap(pq.heapKind)
+	}
 
 	actualStrategy := strategy
 	if strategy == AutoStrategy {
 		actualStrategy = pq.chooseOptimalStrategy()
+		if a := pq.opts.selector; a != nil && pq.size > pq.opts.selection().SmallSize {
+			var record func()
+			actualStrategy, record = pq.adapt(a, actualStrategy)
+			defer record()
+		}
+	}
+
+	if pq.refs != nil {
+		pq.sortTracked(actualStrategy)
+		return
 	}
 
 	switch actualStrategy {
@@ -208,30 +327,42 @@ func (pq *PQueue[T]) SortWithStrategy(strategy SortStrategy) {
 		pq.mergeSort()
 	case QuickStrategy:
 		pq.quickSort()
+	case PdqStrategy:
+		pq.pdqsort()
+	case ParallelMergeStrategy:
+		pq.parallelMergeSort()
 	case RadixStrategy:
-		if pq.dataType == IntegerType {
-			pq.radixSort()
-		} else {
-			pq.quickSort() // fallback
-		}
+		pq.radixSort() // falls back to pdqsort for non-numeric data
 	case CountingStrategy:
-		if pq.dataType == IntegerType {
-			pq.countingSort()
-		} else {
-			pq.quickSort() // fallback
-		}
+		pq.countingSort() // falls back to pdqsort for non-integer data
+	case MSDRadixStrategy:
+		pq.msdRadixSort() // falls back to pdqsort for non-sequence data
 	default:
-		pq.quickSort()
+		pq.pdqsort()
 	}
 }
 
 // ToSlice returns a copy of the internal data
 func (pq *PQueue[T]) ToSlice() []T {
 	result := make([]T, pq.size)
+	if pq.nodes != nil {
+		result = result[:0]
+		pq.nodes.each(func(n *heapNode[T]) bool {
+			result = append(result, n.value)
+			return true
+		})
+		return result
+	}
 	copy(result, pq.data[:pq.size])
 	return result
 }
 
+// GetHeapKind returns the heap implementation currently backing the queue.
+// With AutoHeap this reports the backend chosen from the observed workload.
+func (pq *PQueue[T]) GetHeapKind() HeapKind {
+	return pq.heapKind
+}
+
 // GetDataType returns the inferred data type for debugging purposes
 func (pq *PQueue[T]) GetDataType() DataType {
 	return pq.dataType
@@ -239,7 +370,12 @@ func (pq *PQueue[T]) GetDataType() DataType {
 
 // GetDataTypeName returns a human-readable name for the data type
 func (pq *PQueue[T]) GetDataTypeName() string {
-	switch pq.dataType {
+	return dataTypeName(pq.dataType)
+}
+
+// dataTypeName returns a human-readable name for t
+func dataTypeName(t DataType) string {
+	switch t {
 	case IntegerType:
 		return "Integer"
 	case FloatType:
@@ -310,58 +446,73 @@ func inferDataType[T any](data []T) DataType {
 
 // chooseOptimalStrategy selects the best sorting algorithm based on data characteristics
 func (pq *PQueue[T]) chooseOptimalStrategy() SortStrategy {
+	strategy, _ := pq.selectStrategy()
+	return strategy
+}
+
+// selectStrategy is chooseOptimalStrategy, also describing the rule that
+// made the choice
+func (pq *PQueue[T]) selectStrategy() (SortStrategy, string) {
 	n := pq.size
+	th := pq.opts.selection()
 
 	// For very small arrays, use insertion sort
-	if n <= 16 {
-		return InsertionStrategy
+	if n <= th.SmallSize {
+		return InsertionStrategy, "small input"
 	}
 
-	// Check if data is nearly sorted
-	if pq.isNearlySorted() {
-		return InsertionStrategy
+	// Check if data is nearly sorted. Few descents alone do not make
+	// insertion sort cheap: a rotated input has one descent but inversions
+	// in half its pairs, so sampled inversions must be few as well.
+	descents := pq.sampleDescents(th.SampleSize)
+	if descents <= th.NearlySorted && pq.isNearlySorted(th.SampleSize) {
+		return InsertionStrategy, "nearly sorted"
 	}
 
-	// For integer data with small range, use counting or radix sort
-	if pq.dataType == IntegerType && n > 100 {
-		if pq.hasSmallRange() {
-			return CountingStrategy
+	// For integer data with small range, use counting or radix sort. The
+	// sampled range may miss outliers; counting sort checks the full range
+	// and hands wide ones to radix sort.
+	if _, ok := pq.integers(); ok && n > th.IntegerMinSize {
+		if r, _ := pq.sampleKeyRange(th.SampleSize); r <= th.SmallRange {
+			return CountingStrategy, "integers with a small range"
 		}
-		return RadixStrategy
+		return RadixStrategy, "integers"
 	}
 
-	// For strings, use specialized string sorting
-	if pq.dataType == StringType {
-		if n > 1000 {
-			return IntrosortStrategy // Good for large string datasets
-		}
-		return TimsortStrategy // Good for strings with patterns
+	// For large float data, radix sort the IEEE-754 bit patterns
+	if _, ok := pq.floats(); ok && n > th.FloatMinSize {
+		return RadixStrategy, "floats"
 	}
 
-	// For slices and arrays, use stable sorting
-	if pq.dataType == SliceType || pq.dataType == ArrayType {
-		return MergeStrategy // Stable and predictable
+	// For strings and byte or rune slices with long shared prefixes, sort
+	// symbol by symbol so each prefix is examined only once
+	if s, ok := pq.sequences(); ok && n > th.SequenceMinSize && s.commonPrefix() >= msdPrefixThreshold {
+		return MSDRadixStrategy, "sequences with a long common prefix"
 	}
 
-	// For structs and complex types, use comparison-based sorts
-	if pq.dataType == StructType || pq.dataType == InterfaceType {
-		if n > 1000 {
-			return IntrosortStrategy
-		}
-		return TimsortStrategy
+	// For inputs made of long ascending runs, such as rotated or
+	// concatenated sorted inputs, merge the runs
+	if runs := 1 + descents*float64(n-1); float64(n) >= runs*float64(th.LongRuns) {
+		return TimsortStrategy, "long ascending runs"
 	}
 
-	// For pointers, maps, channels, functions - use generic approach
-	if pq.dataType == PointerType || pq.dataType == MapType ||
-		pq.dataType == ChannelType || pq.dataType == FuncType {
-		return QuickStrategy // Simple and effective for these types
+	// For inputs with few distinct values, pdqsort's equal partitions
+	// settle each value in one pass
+	if dup, entropy := pq.distribution(pq.sampleValues(th.SampleSize)); dup >= th.ManyDuplicates || entropy <= th.LowEntropy {
+		return PdqStrategy, "few distinct values"
 	}
 
-	// For large datasets, use introsort (hybrid approach)
-	if n > 1000 {
-		return IntrosortStrategy
+	// For large inputs, sort chunks on several cores and merge them
+	if n >= pq.opts.parallelThreshold() && pq.opts.workerCount() > 1 {
+		return ParallelMergeStrategy, "large input with several workers"
+	}
+
+	// For slices and arrays, use stable sorting
+	if pq.dataType == SliceType || pq.dataType == ArrayType {
+		return MergeStrategy, "slice or array elements" // Stable and predictable
 	}
 
-	// Default to timsort for general purpose
-	return TimsortStrategy
+	// Default to pdqsort for general purpose: it adapts to sorted, reversed
+	// and repetitive input and cannot degrade to quadratic time
+	return PdqStrategy, "default"
 }
@@ -0,0 +1,275 @@
+package pqueue
+
+import "math"
+
+// Thresholds tune the rules AutoStrategy selects a sort by. Zero fields
+// take their value from DefaultThresholds.
+type Thresholds struct {
+	SmallSize       int     // inputs up to this size use insertion sort
+	NearlySorted    float64 // inputs with at most this fraction of descents and few inversions use insertion sort
+	IntegerMinSize  int     // integer inputs larger than this use counting or radix sort
+	SmallRange      uint64  // integer key ranges up to this use counting sort
+	FloatMinSize    int     // float inputs larger than this use radix sort
+	SequenceMinSize int     // string and sequence inputs larger than this may use MSD radix sort
+	LongRuns        int     // inputs whose ascending runs average at least this length use Timsort
+	ManyDuplicates  float64 // inputs with at least this fraction of duplicates use pdqsort
+	LowEntropy      float64 // inputs with at most this many bits of entropy use pdqsort
+	SampleSize      int     // elements or pairs each profile measure examines
+}
+
+// DefaultThresholds returns the thresholds AutoStrategy uses unless
+// WithThresholds says otherwise
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		SmallSize:       16,
+		NearlySorted:    0.1,
+		IntegerMinSize:  100,
+		SmallRange:      1000,
+		FloatMinSize:    256,
+		SequenceMinSize: 256,
+		LongRuns:        32,
+		ManyDuplicates:  0.95,
+		LowEntropy:      4,
+		SampleSize:      1024,
+	}
+}
+
+// withDefaults fills the zero fields of t from DefaultThresholds
+func (t Thresholds) withDefaults() Thresholds {
+	d := DefaultThresholds()
+	if t.SmallSize == 0 {
+		t.SmallSize = d.SmallSize
+	}
+	if t.NearlySorted == 0 {
+		t.NearlySorted = d.NearlySorted
+	}
+	if t.IntegerMinSize == 0 {
+		t.IntegerMinSize = d.IntegerMinSize
+	}
+	if t.SmallRange == 0 {
+		t.SmallRange = d.SmallRange
+	}
+	if t.FloatMinSize == 0 {
+		t.FloatMinSize = d.FloatMinSize
+	}
+	if t.SequenceMinSize == 0 {
+		t.SequenceMinSize = d.SequenceMinSize
+	}
+	if t.LongRuns == 0 {
+		t.LongRuns = d.LongRuns
+	}
+	if t.ManyDuplicates == 0 {
+		t.ManyDuplicates = d.ManyDuplicates
+	}
+	if t.LowEntropy == 0 {
+		t.LowEntropy = d.LowEntropy
+	}
+	if t.SampleSize <= 0 {
+		t.SampleSize = d.SampleSize
+	}
+	return t
+}
+
+// selection returns the thresholds AutoStrategy selects a sort by
+func (o options) selection() Thresholds {
+	return o.thresholds.withDefaults()
+}
+
+// Profile describes how the elements of a queue are distributed. The
+// measures are estimated from a bounded pseudo-random sample, so they cost
+// the same at any size; inputs no larger than the sample are measured
+// exactly, apart from Inversions.
+type Profile struct {
+	Size        int     // number of elements
+	SampleSize  int     // elements or pairs each measure examines at most
+	Descents    float64 // fraction of adjacent pairs out of order
+	Runs        int     // ascending runs, estimated from Descents
+	Inversions  float64 // fraction of all pairs out of order: 0 sorted, about 0.5 random, 1 reversed
+	Duplicates  float64 // fraction of sampled elements equal to an earlier sampled element
+	Entropy     float64 // Shannon entropy of the sampled values in bits
+	KeyRange    uint64  // largest minus smallest sampled integer, if HasKeyRange
+	HasKeyRange bool    // elements are integers in their natural order
+}
+
+// Profile measures the distribution of the queue's elements without
+// modifying the queue. WithThresholds sets the sample size.
+func (pq *PQueue[T]) Profile() Profile {
+	return pq.view().profile(pq.opts.selection().SampleSize)
+}
+
+// view returns pq, or a flat copy of it for node backends, whose elements
+// are not held in data
+func (pq *PQueue[T]) view() *PQueue[T] {
+	if pq.nodes == nil {
+		return pq
+	}
+	return &PQueue[T]{
+		data:     pq.ToSlice(),
+		less:     pq.less,
+		dataType: pq.dataType,
+		size:     pq.size,
+		opts:     pq.opts,
+		natural:  pq.natural,
+	}
+}
+
+// profile measures data from samples of m elements or pairs
+func (pq *PQueue[T]) profile(m int) Profile {
+	p := Profile{
+		Size:       pq.size,
+		SampleSize: m,
+		Descents:   pq.sampleDescents(m),
+		Inversions: pq.sampleInversions(m),
+	}
+	p.Runs = 1 + int(math.Round(p.Descents*float64(max(pq.size-1, 0))))
+	if pq.size == 0 {
+		p.Runs = 0
+	}
+	p.Duplicates, p.Entropy = pq.distribution(pq.sampleValues(m))
+	p.KeyRange, p.HasKeyRange = pq.sampleKeyRange(m)
+	return p
+}
+
+// sampler draws pseudo-random indices with xorshift64. It is seeded from
+// the input size, so profiles of the same data agree.
+type sampler struct {
+	state uint64
+}
+
+func newSampler(n int) *sampler {
+	return &sampler{state: uint64(n)*0x9e3779b97f4a7c15 | 1}
+}
+
+// index returns an index below n
+func (s *sampler) index(n int) int {
+	s.state ^= s.state << 13
+	s.state ^= s.state >> 7
+	s.state ^= s.state << 17
+	return int(s.state % uint64(n))
+}
+
+// stratum returns an index in the kth of m equal parts of [0, n), for
+// m <= n, so a sample drawn one per part holds no index twice and covers
+// the whole range
+func (s *sampler) stratum(n, m, k int) int {
+	lo, hi := k*n/m, (k+1)*n/m
+	return lo + s.index(hi-lo)
+}
+
+// sampleDescents estimates the fraction of adjacent pairs of data that are
+// out of order from m pairs
+func (pq *PQueue[T]) sampleDescents(m int) float64 {
+	n, d := pq.size, pq.data
+	if n < 2 {
+		return 0
+	}
+
+	descents := 0
+	if n-1 <= m {
+		for i := 1; i < n; i++ {
+			if pq.less(d[i], d[i-1]) {
+				descents++
+			}
+		}
+		return float64(descents) / float64(n-1)
+	}
+
+	s := newSampler(n)
+	for k := 0; k < m; k++ {
+		i := s.stratum(n-1, m, k)
+		if pq.less(d[i+1], d[i]) {
+			descents++
+		}
+	}
+	return float64(descents) / float64(m)
+}
+
+// sampleInversions estimates the fraction of pairs of data that are out of
+// order from m pairs of distinct positions
+func (pq *PQueue[T]) sampleInversions(m int) float64 {
+	n, d := pq.size, pq.data
+	if n < 2 {
+		return 0
+	}
+
+	s := newSampler(n)
+	inversions := 0
+	for pairs := 0; pairs < m; {
+		i, j := s.index(n), s.index(n)
+		if i == j {
+			continue
+		}
+		if i > j {
+			i, j = j, i
+		}
+		if pq.less(d[j], d[i]) {
+			inversions++
+		}
+		pairs++
+	}
+	return float64(inversions) / float64(m)
+}
+
+// sampleValues returns a copy of m elements of data, or all of them if
+// there are no more than m
+func (pq *PQueue[T]) sampleValues(m int) []T {
+	n := pq.size
+	if n <= m {
+		values := make([]T, n)
+		copy(values, pq.data[:n])
+		return values
+	}
+
+	s := newSampler(n)
+	values := make([]T, m)
+	for k := range values {
+		values[k] = pq.data[s.stratum(n, m, k)]
+	}
+	return values
+}
+
+// distribution sorts values and returns the fraction of them equal to an
+// earlier one and the Shannon entropy of their frequencies in bits
+func (pq *PQueue[T]) distribution(values []T) (duplicates, entropy float64) {
+	n := len(values)
+	if n == 0 {
+		return 0, 0
+	}
+	sliceQueue(values, pq.less).pdqsort()
+
+	distinct := 0
+	for i := 0; i < n; {
+		j := i + 1
+		for j < n && !pq.less(values[i], values[j]) {
+			j++
+		}
+		p := float64(j-i) / float64(n)
+		entropy -= p * math.Log2(p)
+		distinct++
+		i = j
+	}
+	return float64(n-distinct) / float64(n), entropy
+}
+
+// sampleKeyRange returns the range of m integer keys of data, which is a
+// lower bound on the true range when data holds more than m elements
+func (pq *PQueue[T]) sampleKeyRange(m int) (uint64, bool) {
+	s, ok := pq.integers()
+	n := pq.size
+	if !ok || n == 0 {
+		return 0, false
+	}
+	if n <= m {
+		lo, hi := s.keyRange()
+		return hi - lo, true
+	}
+
+	r := newSampler(n)
+	lo := s.key(r.stratum(n, m, 0))
+	hi := lo
+	for k := 1; k < m; k++ {
+		key := s.key(r.stratum(n, m, k))
+		lo, hi = min(lo, key), max(hi, key)
+	}
+	return hi - lo, true
+}
@@ -0,0 +1,258 @@
+package pqueue
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// TestProfile tests the measures on inputs with known distributions
+func TestProfile(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	near := func(got, want, tolerance float64) bool { return math.Abs(got-want) <= tolerance }
+
+	ascending := make([]int, 100000)
+	descending := make([]int, 100000)
+	for i := range ascending {
+		ascending[i] = i
+		descending[i] = -i
+	}
+	p := sliceQueue(ascending, less).Profile()
+	if p.Descents != 0 || p.Inversions != 0 || p.Runs != 1 || p.Duplicates != 0 {
+		t.Errorf("Ascending: %+v", p)
+	}
+	p = sliceQueue(descending, less).Profile()
+	if p.Descents != 1 || p.Inversions != 1 || p.Runs != len(descending) {
+		t.Errorf("Descending: %+v", p)
+	}
+
+	random := generateRandomInts(100000)
+	p = sliceQueue(random, less).Profile()
+	if !near(p.Descents, 0.5, 0.06) || !near(p.Inversions, 0.5, 0.06) || p.Duplicates > 0.01 || p.SampleSize != 1024 {
+		t.Errorf("Random: %+v", p)
+	}
+	if !near(p.Entropy, math.Log2(1024), 0.1) {
+		t.Errorf("Random: entropy %v of distinct values", p.Entropy)
+	}
+
+	fewValues := make([]int, 100000)
+	for i := range fewValues {
+		fewValues[i] = rand.Intn(4)
+	}
+	p = sliceQueue(fewValues, less).Profile()
+	if p.Duplicates < 0.99 || !near(p.Entropy, 2, 0.05) {
+		t.Errorf("Few values: %+v", p)
+	}
+
+	if p := sliceQueue([]int{}, less).Profile(); p.Runs != 0 || p.Entropy != 0 {
+		t.Errorf("Empty: %+v", p)
+	}
+}
+
+// TestProfileKeyRange tests that the range is exact for inputs no larger
+// than the sample and a lower bound otherwise
+func TestProfileKeyRange(t *testing.T) {
+	small := []int{5, -3, 12, 7}
+	if p := NewInts(small).Profile(); !p.HasKeyRange || p.KeyRange != 15 {
+		t.Errorf("Small: %+v", p)
+	}
+
+	data := generateRandomInts(50000)
+	lo, hi := slices.Min(data), slices.Max(data)
+	p := NewInts(data).Profile()
+	if !p.HasKeyRange || p.KeyRange > uint64(hi-lo) || p.KeyRange < uint64(hi-lo)*9/10 {
+		t.Errorf("Large: range %d, true range %d", p.KeyRange, hi-lo)
+	}
+
+	if p := New(data, func(a, b int) bool { return a > b }).Profile(); p.HasKeyRange {
+		t.Error("Expected no key range for a custom order")
+	}
+}
+
+// TestProfileQueue tests that profiling is repeatable and leaves every
+// backend untouched
+func TestProfileQueue(t *testing.T) {
+	data := generateRandomInts(5000)
+	for _, hk := range allHeapKinds {
+		pq := NewInts(data, WithHeapKind(hk.kind))
+		before := pq.ToSlice()
+		if a, b := pq.Profile(), pq.Profile(); a != b {
+			t.Errorf("%s: profiles differ: %+v and %+v", hk.name, a, b)
+		}
+		if !slices.Equal(pq.ToSlice(), before) {
+			t.Errorf("%s: Profile modified the queue", hk.name)
+		}
+	}
+}
+
+// TestThresholds tests that WithThresholds changes which rule fires
+func TestThresholds(t *testing.T) {
+	if got := (Thresholds{SmallSize: 5}).withDefaults(); got.SmallSize != 5 || got.SmallRange != 1000 || got.SampleSize != 1024 {
+		t.Errorf("withDefaults: %+v", got)
+	}
+
+	smallRange := make([]int, 1000)
+	for i := range smallRange {
+		smallRange[i] = rand.Intn(50)
+	}
+	swapped := make([]int, 1000)
+	for i := range swapped {
+		swapped[i] = i
+	}
+	for i := 0; i+1 < len(swapped); i += 20 {
+		swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+	}
+	tests := []struct {
+		name       string
+		data       []int
+		thresholds Thresholds
+		rule       string
+	}{
+		{"default", smallRange, Thresholds{}, "integers with a small range"},
+		{"small size", smallRange, Thresholds{SmallSize: 1000}, "small input"},
+		{"nearly sorted", swapped, Thresholds{}, "nearly sorted"},
+		{"descents", swapped, Thresholds{NearlySorted: 0.01}, "integers with a small range"},
+		{"small range", smallRange, Thresholds{SmallRange: 10}, "integers"},
+		{"integer size", smallRange, Thresholds{IntegerMinSize: 1000}, "few distinct values"},
+		{"distinct", smallRange, Thresholds{IntegerMinSize: 1000, ManyDuplicates: 1, LowEntropy: 1}, "default"},
+	}
+	for _, tt := range tests {
+		pq := NewInts(tt.data, WithThresholds(tt.thresholds), WithWorkers(1))
+		if plan := pq.Explain(); plan.Rule != tt.rule {
+			t.Errorf("%s: rule %q, want %q", tt.name, plan.Rule, tt.rule)
+		}
+	}
+
+	if p := NewInts(smallRange, WithThresholds(Thresholds{SampleSize: 64})).Profile(); p.SampleSize != 64 {
+		t.Errorf("SampleSize %d, want 64", p.SampleSize)
+	}
+}
+
+// TestNearlySortedInversions tests that inputs with few descents but many
+// inversions are not handed to insertion sort
+func TestNearlySortedInversions(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	rotated := func(n int) []int {
+		s := make([]int, n)
+		for i := range s {
+			s[i] = (i + n/2) % n
+		}
+		return s
+	}
+	sawtooth := make([]int, 10000)
+	for i := range sawtooth {
+		sawtooth[i] = i % 200
+	}
+
+	for name, data := range map[string][]int{
+		"rotated 1000":  rotated(1000),
+		"rotated 80000": rotated(80000),
+		"sawtooth":      sawtooth,
+	} {
+		pq := sliceQueue(slices.Clone(data), less)
+		plan := pq.Explain()
+		if plan.Strategy != TimsortStrategy {
+			t.Errorf("%s: %v chosen by %q, want Timsort for its runs", name, plan.Strategy, plan.Rule)
+		}
+		report := pq.SortWithReport()
+		if n := len(data); report.Comparisons > 4*n*bits.Len(uint(n)) {
+			t.Errorf("%s: %d comparisons sorting %d elements", name, report.Comparisons, n)
+		}
+		if !slices.IsSorted(pq.ToSlice()) {
+			t.Errorf("%s: not sorted", name)
+		}
+
+		SortSlice(data, less)
+		if !slices.IsSorted(data) {
+			t.Errorf("%s: SortSlice did not sort", name)
+		}
+	}
+}
+
+// TestDistributionRules tests the rules driven by runs, duplicates and
+// entropy and the thresholds that move them
+func TestDistributionRules(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	sawtooth := make([]int, 10000)
+	for i := range sawtooth {
+		sawtooth[i] = i % 200
+	}
+	values := func(k int) []int {
+		s := make([]int, 5000)
+		for i := range s {
+			s[i] = rand.Intn(k)
+		}
+		return s
+	}
+
+	parallel := []Option{WithWorkers(4), WithParallelThreshold(1000)}
+
+	tests := []struct {
+		name       string
+		pq         *PQueue[int]
+		thresholds Thresholds
+		rule       string
+	}{
+		{"runs", sliceQueue(sawtooth, less), Thresholds{}, "long ascending runs"},
+		{"short runs", sliceQueue(sawtooth, less), Thresholds{LongRuns: 500}, "default"},
+		{"duplicates", New(values(40), less, parallel...), Thresholds{}, "few distinct values"},
+		{"entropy", New(values(8), less, parallel...), Thresholds{ManyDuplicates: 1}, "few distinct values"},
+		{"distinct", New(values(8), less, parallel...), Thresholds{ManyDuplicates: 1, LowEntropy: 0.5}, "large input with several workers"},
+	}
+	for _, tt := range tests {
+		tt.pq.opts.thresholds = tt.thresholds
+		if plan := tt.pq.Explain(); plan.Rule != tt.rule {
+			t.Errorf("%s: rule %q, want %q", tt.name, plan.Rule, tt.rule)
+		}
+	}
+}
+
+// TestCountingSortRange tests that counting sort honors SmallRange beyond
+// the default and hands ranges too wide to count to radix sort
+func TestCountingSortRange(t *testing.T) {
+	data := make([]int, 100000)
+	for i := range data {
+		data[i] = rand.Intn(50000)
+	}
+	pq := NewInts(slices.Clone(data), WithThresholds(Thresholds{SmallRange: 1 << 16}))
+	report := pq.SortWithReport()
+	if report.Strategy != CountingStrategy || report.Comparisons != 0 {
+		t.Errorf("Report %v with %d comparisons, want counting sort without any", report.Strategy, report.Comparisons)
+	}
+	slices.Sort(data)
+	if !slices.Equal(pq.ToSlice(), data) {
+		t.Error("Not sorted")
+	}
+
+	data[0] = 1 << 60
+	pq = NewInts(slices.Clone(data))
+	pq.SortWithStrategy(CountingStrategy)
+	slices.Sort(data)
+	if !slices.Equal(pq.ToSlice(), data) {
+		t.Error("Wide range not sorted")
+	}
+}
+
+// TestSampledRangeOutlier tests that counting sort chosen from a sample
+// that missed an outlier still sorts correctly, by radix sort
+func TestSampledRangeOutlier(t *testing.T) {
+	data := make([]int, 200000)
+	for i := range data {
+		data[i] = rand.Intn(100)
+	}
+	data[rand.Intn(len(data))] = 1 << 40
+
+	pq := NewInts(data)
+	if plan := pq.Explain(); plan.Strategy != CountingStrategy {
+		t.Skipf("Sample found the outlier: %+v", plan.Profile)
+	}
+	if report := pq.SortWithReport(); report.Comparisons != 0 {
+		t.Errorf("%d comparisons, want the radix fallback", report.Comparisons)
+	}
+	slices.Sort(data)
+	if !slices.Equal(pq.ToSlice(), data) {
+		t.Error("Not sorted")
+	}
+}
@@ -0,0 +1,288 @@
+package pqueue
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// integer is satisfied by every built-in signed and unsigned integer type
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Limits on the counters countingSortIntegers allocates before it switches
+// to radix sort: a few per element, and never more than maxCounters. A
+// range the width of a byte is always counted.
+const (
+	maxCountingRatio = 8
+	maxCounters      = 1 << 20
+	minCounters      = 1 << 8
+)
+
+// float is satisfied by every floating-point type
+type float interface {
+	~float32 | ~float64
+}
+
+// integerSorter sorts queue data of a built-in integer type by value
+// rather than through less
+type integerSorter interface {
+	radixSort()
+	countingSort()
+	keyRange() (lo, hi uint64)
+	key(i int) uint64
+}
+
+// intSlice implements integerSorter over a slice that aliases queue data
+type intSlice[E integer] []E
+
+func (s intSlice[E]) radixSort() {
+	radixSortIntegers(s)
+}
+
+func (s intSlice[E]) countingSort() {
+	countingSortIntegers(s)
+}
+
+func (s intSlice[E]) keyRange() (lo, hi uint64) {
+	return integerKeyRange(s)
+}
+
+func (s intSlice[E]) key(i int) uint64 {
+	return integerKey(s[i])
+}
+
+// integers returns a sorter over the queue data when T is of an integer
+// kind, including named types such as time.Duration, and the queue is in
+// its natural ascending order. The data is reinterpreted in place as the
+// matching built-in type, which has the same memory layout.
+func (pq *PQueue[T]) integers() (integerSorter, bool) {
+	if !pq.natural {
+		return nil, false
+	}
+
+	p, n := unsafe.Pointer(unsafe.SliceData(pq.data)), pq.size
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.Int:
+		return intSlice[int](unsafe.Slice((*int)(p), n)), true
+	case reflect.Int8:
+		return intSlice[int8](unsafe.Slice((*int8)(p), n)), true
+	case reflect.Int16:
+		return intSlice[int16](unsafe.Slice((*int16)(p), n)), true
+	case reflect.Int32:
+		return intSlice[int32](unsafe.Slice((*int32)(p), n)), true
+	case reflect.Int64:
+		return intSlice[int64](unsafe.Slice((*int64)(p), n)), true
+	case reflect.Uint:
+		return intSlice[uint](unsafe.Slice((*uint)(p), n)), true
+	case reflect.Uint8:
+		return intSlice[uint8](unsafe.Slice((*uint8)(p), n)), true
+	case reflect.Uint16:
+		return intSlice[uint16](unsafe.Slice((*uint16)(p), n)), true
+	case reflect.Uint32:
+		return intSlice[uint32](unsafe.Slice((*uint32)(p), n)), true
+	case reflect.Uint64:
+		return intSlice[uint64](unsafe.Slice((*uint64)(p), n)), true
+	case reflect.Uintptr:
+		return intSlice[uintptr](unsafe.Slice((*uintptr)(p), n)), true
+	}
+	return nil, false
+}
+
+// integerKey maps v to an unsigned key with the same order: the value is
+// truncated to its own width and signed types have their sign bit flipped
+func integerKey[E integer](v E) uint64 {
+	bits := 8 * unsafe.Sizeof(v)
+	k := uint64(v) & (^uint64(0) >> (64 - bits))
+	if ^E(0) < 0 {
+		k ^= 1 << (bits - 1)
+	}
+	return k
+}
+
+// integerKeyRange returns the smallest and largest key in s
+func integerKeyRange[E integer](s []E) (lo, hi uint64) {
+	if len(s) == 0 {
+		return 0, 0
+	}
+	lo = integerKey(s[0])
+	hi = lo
+	for _, v := range s[1:] {
+		k := integerKey(v)
+		if k < lo {
+			lo = k
+		}
+		if k > hi {
+			hi = k
+		}
+	}
+	return lo, hi
+}
+
+// radixSortIntegers sorts s with a byte-wise LSD radix sort: one stable
+// 256-bucket counting pass per key byte, skipping bytes all keys share
+func radixSortIntegers[E integer](s []E) {
+	n := len(s)
+	if n < 2 {
+		return
+	}
+	width := int(unsafe.Sizeof(s[0]))
+
+	// Histogram every byte position in a single pass
+	var counts [8][256]int
+	for _, v := range s {
+		k := integerKey(v)
+		for b := 0; b < width; b++ {
+			counts[b][byte(k>>(8*b))]++
+		}
+	}
+
+	src, dst := s, make([]E, n)
+	for b := 0; b < width; b++ {
+		c := &counts[b]
+		shift := 8 * b
+		if c[byte(integerKey(src[0])>>shift)] == n {
+			continue // every key has the same byte here
+		}
+
+		offset := 0
+		for i, count := range c {
+			c[i] = offset
+			offset += count
+		}
+		for _, v := range src {
+			d := byte(integerKey(v) >> shift)
+			dst[c[d]] = v
+			c[d]++
+		}
+		src, dst = dst, src
+	}
+
+	if &src[0] != &s[0] {
+		copy(s, src)
+	}
+}
+
+// countingSortIntegers sorts s by counting the occurrences of each value
+// between its minimum and maximum, allocating one counter per value. Inputs
+// whose range is too wide to count are radix sorted instead.
+func countingSortIntegers[E integer](s []E) {
+	if len(s) < 2 {
+		return
+	}
+	lo, hi := integerKeyRange(s)
+	if hi-lo >= min(max(uint64(len(s))*maxCountingRatio, minCounters), maxCounters) {
+		radixSortIntegers(s)
+		return
+	}
+
+	count := make([]int, hi-lo+1)
+	minVal := s[0]
+	for _, v := range s {
+		count[integerKey(v)-lo]++
+		if v < minVal {
+			minVal = v
+		}
+	}
+
+	// Adding the offset in E wraps around exactly like the keys do, so
+	// ranges spanning zero or reaching the type's limits are rebuilt intact
+	pos := 0
+	for i, c := range count {
+		v := minVal + E(i)
+		for ; c > 0; c-- {
+			s[pos] = v
+			pos++
+		}
+	}
+}
+
+// floats returns the queue data as a slice of its floating-point kind when
+// the queue is in its natural ascending order
+func (pq *PQueue[T]) floats() (floatSorter, bool) {
+	if !pq.natural {
+		return nil, false
+	}
+
+	p, n := unsafe.Pointer(unsafe.SliceData(pq.data)), pq.size
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.Float32:
+		return floatSlice[float32](unsafe.Slice((*float32)(p), n)), true
+	case reflect.Float64:
+		return floatSlice[float64](unsafe.Slice((*float64)(p), n)), true
+	}
+	return nil, false
+}
+
+// floatSorter sorts queue data of a floating-point kind by value
+type floatSorter interface {
+	radixSort(order NaNOrder)
+}
+
+// floatSlice implements floatSorter over a slice that aliases queue data
+type floatSlice[F float] []F
+
+func (s floatSlice[F]) radixSort(order NaNOrder) {
+	radixSortFloats(s, order)
+}
+
+// radixSortFloats sorts s by radix sorting the IEEE-754 bit patterns as
+// unsigned keys. Negative numbers have all bits flipped and positive ones
+// only the sign bit, which orders -Inf < finite values < +Inf and -0 before
+// +0. NaNs are set aside first and placed at the end order selects;
+// NaNReject is treated as NaNFirst.
+func radixSortFloats[F float](s []F, order NaNOrder) {
+	// Compact the numbers to the front, keeping NaN payloads intact
+	n := 0
+	var nans []F
+	for _, v := range s {
+		if v != v {
+			nans = append(nans, v)
+			continue
+		}
+		s[n] = v
+		n++
+	}
+
+	p := unsafe.Pointer(unsafe.SliceData(s))
+	if unsafe.Sizeof(s[0]) == 4 {
+		sortFloatBits(unsafe.Slice((*uint32)(p), n))
+	} else {
+		sortFloatBits(unsafe.Slice((*uint64)(p), n))
+	}
+
+	if len(nans) == 0 {
+		return
+	}
+	if order == NaNLast {
+		copy(s[n:], nans)
+		return
+	}
+	copy(s[len(nans):], s[:n])
+	copy(s, nans)
+}
+
+// sortFloatBits sorts the bit patterns of non-NaN floats in place by
+// mapping them to order-preserving keys, radix sorting those and mapping
+// them back
+func sortFloatBits[U uint32 | uint64](bits []U) {
+	sign := U(1) << (8*unsafe.Sizeof(U(0)) - 1)
+	for i, b := range bits {
+		if b&sign != 0 {
+			bits[i] = ^b
+		} else {
+			bits[i] = b | sign
+		}
+	}
+
+	radixSortIntegers(bits)
+
+	for i, k := range bits {
+		if k&sign != 0 {
+			bits[i] = k &^ sign
+		} else {
+			bits[i] = ^k
+		}
+	}
+}
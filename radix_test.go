@@ -0,0 +1,334 @@
+package pqueue
+
+import (
+	"cmp"
+	"math"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// checkIntegerSort sorts random values of E with radix and counting sort
+// and compares the results with slices.Sort
+func checkIntegerSort[E integer](t *testing.T, name string, gen func() E) {
+	t.Helper()
+	for _, strategy := range []SortStrategy{AutoStrategy, RadixStrategy, CountingStrategy} {
+		data := make([]E, 2000)
+		for i := range data {
+			data[i] = gen()
+		}
+		want := slices.Clone(data)
+		slices.Sort(want)
+
+		pq := NewOrdered(data)
+		pq.SortWithStrategy(strategy)
+		if got := pq.ToSlice(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s with strategy %v: result not sorted", name, strategy)
+		}
+	}
+}
+
+// TestRadixAllIntegerTypes tests the key-based sorts on every integer kind
+func TestRadixAllIntegerTypes(t *testing.T) {
+	checkIntegerSort(t, "int", func() int { return rand.Int() - rand.Int() })
+	checkIntegerSort(t, "int8", func() int8 { return int8(rand.Uint32()) })
+	checkIntegerSort(t, "int16", func() int16 { return int16(rand.Uint32()) })
+	checkIntegerSort(t, "int32", func() int32 { return int32(rand.Uint32()) })
+	checkIntegerSort(t, "int64", func() int64 { return int64(rand.Uint64()) })
+	checkIntegerSort(t, "uint", func() uint { return uint(rand.Uint64()) })
+	checkIntegerSort(t, "uint8", func() uint8 { return uint8(rand.Uint32()) })
+	checkIntegerSort(t, "uint16", func() uint16 { return uint16(rand.Uint32()) })
+	checkIntegerSort(t, "uint32", func() uint32 { return rand.Uint32() })
+	checkIntegerSort(t, "uint64", func() uint64 { return rand.Uint64() })
+	checkIntegerSort(t, "uintptr", func() uintptr { return uintptr(rand.Uint64()) })
+
+	// Small ranges take the counting sort path under AutoStrategy
+	checkIntegerSort(t, "int small range", func() int { return rand.Intn(500) - 250 })
+}
+
+// TestRadixRespectsLess tests that key-based sorts are not applied when
+// less is not the natural order of the integers
+func TestRadixRespectsLess(t *testing.T) {
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = rand.Intn(100000)
+	}
+	want := slices.Clone(data)
+	sort.Sort(sort.Reverse(sort.IntSlice(want)))
+
+	for _, strategy := range []SortStrategy{AutoStrategy, RadixStrategy, CountingStrategy} {
+		pq := New(data, func(a, b int) bool { return a > b })
+		pq.SortWithStrategy(strategy)
+		if got := pq.ToSlice(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Strategy %v ignored the descending order", strategy)
+		}
+	}
+
+	b := NewBounded(500, func(a, b int) bool { return a > b })
+	for _, v := range data {
+		b.Push(v)
+	}
+	if got := b.Sorted(); !reflect.DeepEqual(got, want[:500]) {
+		t.Error("BoundedPQueue.Sorted ignored the descending order")
+	}
+}
+
+// TestSortOrderedIntegers tests the zero-copy path on named-width integers
+func TestSortOrderedIntegers(t *testing.T) {
+	data := []int16{300, -5, 0, 32767, -32768, 7}
+	SortOrdered(data)
+	if !reflect.DeepEqual(data, []int16{-32768, -5, 0, 7, 300, 32767}) {
+		t.Errorf("SortOrdered(int16) = %v", data)
+	}
+}
+
+// edgeValues returns the limits of E and the values around every power of
+// two, where byte and sign boundaries of the radix keys lie
+func edgeValues[E integer]() []E {
+	bits := 8 * unsafe.Sizeof(E(0))
+	var lo, hi E
+	if ^E(0) < 0 {
+		lo = E(1) << (bits - 1)
+		hi = lo - 1
+	} else {
+		hi = ^E(0)
+	}
+
+	vals := []E{lo, lo + 1, hi - 1, hi, 0}
+	for b := uintptr(0); b < bits; b++ {
+		v := E(1) << b
+		vals = append(vals, v-1, v, v+1, -v, -v-1, lo+v, hi-v)
+	}
+	return vals
+}
+
+// checkEdgeSort sorts edge values of E, and narrow ranges at both limits
+// of E, with every key-based path
+func checkEdgeSort[E integer](t *testing.T, name string) {
+	t.Helper()
+	edges := edgeValues[E]()
+	lo, hi := slices.Min(edges), slices.Max(edges)
+
+	inputs := map[string][]E{"edges": edges}
+	var low, high []E
+	for i := 0; i < 200; i++ {
+		low = append(low, lo+E(rand.Intn(50)))
+		high = append(high, hi-E(rand.Intn(50)))
+	}
+	inputs["near min"] = low
+	inputs["near max"] = high
+	inputs["across zero"] = append(slices.Clone(low[:100]), high[:100]...)
+
+	for input, data := range inputs {
+		// Repeat and shuffle so every path sees duplicates in random order
+		var values []E
+		for r := 0; r < 4; r++ {
+			values = append(values, data...)
+		}
+		rand.Shuffle(len(values), func(i, j int) { values[i], values[j] = values[j], values[i] })
+		want := slices.Clone(values)
+		slices.Sort(want)
+
+		for _, strategy := range []SortStrategy{AutoStrategy, RadixStrategy, CountingStrategy} {
+			got := slices.Clone(values)
+			pq := sliceQueue(got, func(a, b E) bool { return a < b })
+			pq.natural = true
+			pq.SortWithStrategy(strategy)
+			if !slices.Equal(got, want) {
+				t.Errorf("%s %s with strategy %v: got %v, want %v", name, input, strategy, got, want)
+			}
+		}
+
+		got := slices.Clone(values)
+		countingSortIntegers(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("%s %s with counting sort: got %v, want %v", name, input, got, want)
+		}
+	}
+}
+
+// TestRadixEdgeValues tests the full range of every integer kind
+func TestRadixEdgeValues(t *testing.T) {
+	checkEdgeSort[int](t, "int")
+	checkEdgeSort[int8](t, "int8")
+	checkEdgeSort[int16](t, "int16")
+	checkEdgeSort[int32](t, "int32")
+	checkEdgeSort[int64](t, "int64")
+	checkEdgeSort[uint](t, "uint")
+	checkEdgeSort[uint8](t, "uint8")
+	checkEdgeSort[uint16](t, "uint16")
+	checkEdgeSort[uint32](t, "uint32")
+	checkEdgeSort[uint64](t, "uint64")
+	checkEdgeSort[uintptr](t, "uintptr")
+}
+
+// TestCountingSortOutlier tests that one outlier the sampled range missed
+// does not make counting sort allocate counters for the whole range
+func TestCountingSortOutlier(t *testing.T) {
+	data := make([]int, 100000)
+	for i := range data {
+		data[i] = rand.Intn(1000)
+	}
+	data[rand.Intn(len(data))] = 1e8
+	want := slices.Clone(data)
+	slices.Sort(want)
+
+	pq := NewInts(data)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	pq.SortWithStrategy(CountingStrategy)
+	runtime.ReadMemStats(&after)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 8<<20 {
+		t.Errorf("Allocated %d bytes sorting %d elements", allocated, len(data))
+	}
+	if !slices.Equal(pq.ToSlice(), want) {
+		t.Error("Not sorted")
+	}
+}
+
+// TestRadixNamedIntegerTypes tests that named integer types take the
+// key-based path
+func TestRadixNamedIntegerTypes(t *testing.T) {
+	type priority int8
+
+	durations := []time.Duration{time.Hour, -time.Second, 0, 1<<63 - 1, -1 << 63}
+	pq := NewOrdered(durations)
+	if _, ok := pq.integers(); !ok {
+		t.Fatal("Expected time.Duration to be sorted by key")
+	}
+	pq.SortWithStrategy(RadixStrategy)
+	if got, want := pq.ToSlice(), []time.Duration{-1 << 63, -time.Second, 0, time.Hour, 1<<63 - 1}; !slices.Equal(got, want) {
+		t.Errorf("Radix sort of durations = %v, want %v", got, want)
+	}
+
+	priorities := []priority{5, -128, 127, -1, 0}
+	SortOrdered(priorities)
+	if !slices.Equal(priorities, []priority{-128, -1, 0, 5, 127}) {
+		t.Errorf("SortOrdered(priority) = %v", priorities)
+	}
+}
+
+// floatInput returns random floats mixed with NaNs, zeros of both signs,
+// infinities and subnormals
+func floatInput(n int) []float64 {
+	specials := []float64{
+		math.NaN(), math.Copysign(0, -1), 0, math.Inf(1), math.Inf(-1),
+		math.SmallestNonzeroFloat64, -math.SmallestNonzeroFloat64,
+		math.MaxFloat64, -math.MaxFloat64,
+	}
+	data := make([]float64, n)
+	for i := range data {
+		if rand.Intn(10) == 0 {
+			data[i] = specials[rand.Intn(len(specials))]
+		} else {
+			data[i] = (rand.Float64() - 0.5) * math.Pow(10, float64(rand.Intn(40)-20))
+		}
+	}
+	return data
+}
+
+// checkFloatOrder verifies s is ascending with NaNs at the requested end
+func checkFloatOrder[F float](t *testing.T, name string, s []F, order NaNOrder) {
+	t.Helper()
+	nans := 0
+	for _, v := range s {
+		if v != v {
+			nans++
+		}
+	}
+	numbers := s[nans:]
+	for i := 0; i < nans; i++ {
+		v := s[i]
+		if order == NaNLast {
+			v = s[len(s)-1-i]
+		}
+		if v == v {
+			t.Fatalf("%s: NaNs are not grouped at the expected end", name)
+		}
+	}
+	if order == NaNLast {
+		numbers = s[:len(s)-nans]
+	}
+
+	for i := 1; i < len(numbers); i++ {
+		a, b := float64(numbers[i-1]), float64(numbers[i])
+		if a > b {
+			t.Fatalf("%s: %v before %v at %d", name, a, b, i)
+		}
+	}
+}
+
+// TestRadixFloats tests the IEEE-754 key transform on float32 and float64
+func TestRadixFloats(t *testing.T) {
+	for _, order := range []NaNOrder{NaNFirst, NaNLast} {
+		data := floatInput(3000)
+
+		s64 := slices.Clone(data)
+		pq := sliceQueue(s64, cmp.Less[float64])
+		pq.natural = true
+		pq.opts.nanOrder = order
+		pq.SortWithStrategy(RadixStrategy)
+		checkFloatOrder(t, "float64", s64, order)
+
+		s32 := make([]float32, len(data))
+		for i, v := range data {
+			s32[i] = float32(v)
+		}
+		radixSortFloats(s32, order)
+		checkFloatOrder(t, "float32", s32, order)
+	}
+}
+
+// TestSortFloats tests the NaN orders of SortFloats
+func TestSortFloats(t *testing.T) {
+	for _, order := range []NaNOrder{NaNFirst, NaNLast} {
+		for _, n := range []int{10, 5000} {
+			data := floatInput(n)
+			if err := SortFloats(data, order); err != nil {
+				t.Fatalf("SortFloats: %v", err)
+			}
+			checkFloatOrder(t, "SortFloats", data, order)
+		}
+	}
+
+	data := []float64{3, math.NaN(), 1}
+	if err := SortFloats(data, NaNReject); err != ErrNaN {
+		t.Errorf("Expected ErrNaN, got %v", err)
+	}
+	if data[0] != 3 || data[2] != 1 {
+		t.Errorf("Rejected input was modified: %v", data)
+	}
+	data = []float64{3, 2, 1}
+	if err := SortFloats(data, NaNReject); err != nil || !slices.Equal(data, []float64{1, 2, 3}) {
+		t.Errorf("SortFloats without NaN = %v, %v", data, err)
+	}
+}
+
+// TestFloatQueueNaNOrder tests that queues pop and sort NaNs consistently
+func TestFloatQueueNaNOrder(t *testing.T) {
+	data := floatInput(2000)
+
+	first := NewFloats(data)
+	if v, _ := first.Peek(); !math.IsNaN(v) {
+		t.Errorf("Expected NaN at the front by default, got %v", v)
+	}
+	if first.chooseOptimalStrategy() != RadixStrategy {
+		t.Error("Expected large float input to use radix sort")
+	}
+	first.Sort()
+	checkFloatOrder(t, "NaNFirst queue", first.ToSlice(), NaNFirst)
+	assertHeap(t, first)
+
+	last := NewFloats(data, WithNaNOrder(NaNLast))
+	if v, _ := last.Peek(); math.IsNaN(v) {
+		t.Error("Expected a number at the front with NaNLast")
+	}
+	last.Sort()
+	checkFloatOrder(t, "NaNLast queue", last.ToSlice(), NaNLast)
+	assertHeap(t, last)
+}
@@ -0,0 +1,71 @@
+package pqueue
+
+import (
+	"cmp"
+	"slices"
+)
+
+// SortSlice sorts s in place with the strategy AutoStrategy selects for it.
+// Unlike New followed by Sort and ToSlice, the slice is never copied.
+func SortSlice[T any](s []T, less func(T, T) bool) {
+	SortSliceWithStrategy(s, less, AutoStrategy)
+}
+
+// SortSliceWithStrategy sorts s in place using a specific strategy
+func SortSliceWithStrategy[T any](s []T, less func(T, T) bool, strategy SortStrategy) {
+	sliceQueue(s, less).SortWithStrategy(strategy)
+}
+
+// SortOrdered sorts a slice of an ordered type in ascending order in place.
+// Floating-point NaNs are ordered before all other values, as by cmp.Less.
+func SortOrdered[T cmp.Ordered](s []T) {
+	pq := sliceQueue(s, cmp.Less[T])
+	pq.natural = true
+	pq.Sort()
+}
+
+// SortFloats sorts a slice of floats in ascending order in place, with
+// NaNs placed first or last as order selects. -0 and +0 are equal and may
+// end up in either order. With NaNReject, SortFloats returns ErrNaN and
+// leaves s unchanged if it contains a NaN.
+func SortFloats[F ~float32 | ~float64](s []F, order NaNOrder) error {
+	less := cmp.Less[F]
+	switch order {
+	case NaNLast:
+		less = nanLastLess[F]
+	case NaNReject:
+		if slices.ContainsFunc(s, isNaN[F]) {
+			return ErrNaN
+		}
+	}
+
+	pq := sliceQueue(s, less)
+	pq.natural = true
+	pq.opts.nanOrder = order
+	pq.Sort()
+	return nil
+}
+
+// isNaN reports whether x is a floating-point NaN
+func isNaN[T cmp.Ordered](x T) bool {
+	return x != x
+}
+
+// nanLastLess is cmp.Less with NaNs ordered after every other value
+func nanLastLess[T cmp.Ordered](a, b T) bool {
+	return a < b || (isNaN(b) && !isNaN(a))
+}
+
+// sliceQueue wraps s in a queue without copying it, so the sorting
+// algorithms run directly on the caller's slice. The result is not a heap
+// and must only be sorted.
+func sliceQueue[T any](s []T, less func(T, T) bool) *PQueue[T] {
+	return &PQueue[T]{
+		data:     s,
+		less:     less,
+		size:     len(s),
+		dataType: inferDataType(s),
+		heapKind: BinaryHeap,
+		arity:    2,
+	}
+}
@@ -0,0 +1,79 @@
+package pqueue
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// TestSortSliceInPlace tests that SortSlice sorts the caller's slice
+// without reallocating it
+func TestSortSliceInPlace(t *testing.T) {
+	for _, size := range []int{0, 1, 10, 100, 1000, 5000} {
+		data := make([]int, size)
+		for i := range data {
+			data[i] = rand.Intn(size*10 + 1)
+		}
+		want := append([]int(nil), data...)
+		sort.Ints(want)
+
+		s := data[:len(data):len(data)]
+		SortSlice(s, func(a, b int) bool { return a < b })
+		if size > 0 && &s[0] != &data[0] {
+			t.Errorf("Size %d: slice was reallocated", size)
+		}
+		if size > 0 && !reflect.DeepEqual(s, want) {
+			t.Errorf("Size %d: got %v, want %v", size, s, want)
+		}
+	}
+}
+
+// TestSortSliceWithStrategy tests every strategy on a caller's slice
+func TestSortSliceWithStrategy(t *testing.T) {
+	strategies := []SortStrategy{
+		AutoStrategy, RadixStrategy, CountingStrategy, InsertionStrategy,
+		TimsortStrategy, IntrosortStrategy, MergeStrategy, QuickStrategy, PdqStrategy,
+		ParallelMergeStrategy,
+	}
+	for _, strategy := range strategies {
+		data := make([]int, 500)
+		for i := range data {
+			data[i] = rand.Intn(1000)
+		}
+		want := append([]int(nil), data...)
+		sort.Ints(want)
+
+		SortSliceWithStrategy(data, func(a, b int) bool { return a < b }, strategy)
+		if !reflect.DeepEqual(data, want) {
+			t.Errorf("Strategy %v: got %v, want %v", strategy, data, want)
+		}
+	}
+
+	// A custom order on structs
+	type item struct {
+		name  string
+		score int
+	}
+	items := []item{{"a", 3}, {"b", 9}, {"c", 1}, {"d", 5}}
+	SortSlice(items, func(x, y item) bool { return x.score > y.score })
+	if got := []string{items[0].name, items[1].name, items[2].name, items[3].name}; !reflect.DeepEqual(got, []string{"b", "d", "a", "c"}) {
+		t.Errorf("Descending by score = %v", got)
+	}
+}
+
+// TestSortOrdered tests SortOrdered on strings and floats
+func TestSortOrdered(t *testing.T) {
+	words := []string{"pear", "apple", "fig", "banana"}
+	SortOrdered(words)
+	if !reflect.DeepEqual(words, []string{"apple", "banana", "fig", "pear"}) {
+		t.Errorf("SortOrdered(strings) = %v", words)
+	}
+
+	floats := []float64{2.5, math.NaN(), -1, 0}
+	SortOrdered(floats)
+	if !math.IsNaN(floats[0]) || !reflect.DeepEqual(floats[1:], []float64{-1, 0, 2.5}) {
+		t.Errorf("SortOrdered(floats) = %v", floats)
+	}
+}
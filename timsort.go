@@ -0,0 +1,492 @@
+package pqueue
+
+const (
+	// timsortMinMerge is the input size below which timsort is a single
+	// binary insertion sort, and the bound minrun is computed against
+	timsortMinMerge = 32
+
+	// timsortMinGallop is the initial number of consecutive wins by one run
+	// after which merging switches to galloping
+	timsortMinGallop = 7
+)
+
+// timsorter holds the state of one timsort: the pending runs, the merge
+// buffer and the adaptive galloping threshold
+type timsorter[T any] struct {
+	data      []T
+	less      func(a, b T) bool
+	tmp       []T
+	minGallop int
+
+	// Pending runs, with runBase[i] + runLen[i] == runBase[i+1]
+	runBase []int
+	runLen  []int
+}
+
+// timsort performs Tim Peters' Timsort: natural runs are found and
+// extended to minrun with binary insertion sort, then merged with
+// galloping while the run stack keeps its length invariants. It is stable
+// and uses at most n/2 extra space.
+func (pq *PQueue[T]) timsort() {
+	n := pq.size
+	if n < 2 {
+		return
+	}
+
+	ts := &timsorter[T]{data: pq.data[:n], less: pq.less, minGallop: timsortMinGallop}
+	if n < timsortMinMerge {
+		ts.binaryInsertionSort(0, n, ts.countRun(0, n))
+		return
+	}
+
+	minRun := minRunLength(n)
+	for lo := 0; lo < n; {
+		run := ts.countRun(lo, n)
+		if run < minRun {
+			force := min(n-lo, minRun)
+			ts.binaryInsertionSort(lo, lo+force, lo+run)
+			run = force
+		}
+
+		ts.runBase = append(ts.runBase, lo)
+		ts.runLen = append(ts.runLen, run)
+		ts.mergeCollapse()
+		lo += run
+	}
+	ts.mergeForceCollapse()
+}
+
+// minRunLength returns the minimum run length for n elements: n itself
+// when small, otherwise a value in [16, 32] such that n/minrun is a power
+// of two or slightly less
+func minRunLength(n int) int {
+	r := 0
+	for n >= timsortMinMerge {
+		r |= n & 1
+		n >>= 1
+	}
+	return n + r
+}
+
+// countRun returns the length of the run starting at lo, reversing it if
+// it is descending. Only strictly descending runs are reversed, so equal
+// elements never change their relative order.
+func (ts *timsorter[T]) countRun(lo, hi int) int {
+	d := ts.data
+	i := lo + 1
+	if i == hi {
+		return 1
+	}
+
+	if ts.less(d[i], d[lo]) {
+		for i++; i < hi && ts.less(d[i], d[i-1]); i++ {
+		}
+		for a, b := lo, i-1; a < b; a, b = a+1, b-1 {
+			d[a], d[b] = d[b], d[a]
+		}
+	} else {
+		for i++; i < hi && !ts.less(d[i], d[i-1]); i++ {
+		}
+	}
+	return i - lo
+}
+
+// binaryInsertionSort sorts data[lo:hi] whose prefix data[lo:start] is
+// already sorted. Each element is inserted after any equal ones.
+func (ts *timsorter[T]) binaryInsertionSort(lo, hi, start int) {
+	d := ts.data
+	for ; start < hi; start++ {
+		pivot := d[start]
+		left, right := lo, start
+		for left < right {
+			mid := int(uint(left+right) >> 1)
+			if ts.less(pivot, d[mid]) {
+				right = mid
+			} else {
+				left = mid + 1
+			}
+		}
+		copy(d[left+1:start+1], d[left:start])
+		d[left] = pivot
+	}
+}
+
+// mergeCollapse merges pending runs until, for the top runs A, B, C and D,
+// B > C + D, A > B + C and C > D. This keeps run lengths growing at least
+// as fast as the Fibonacci numbers, bounding the stack to O(log n) runs and
+// keeping merges balanced. The check on A is the fix for the invariant
+// violation found by de Gouw et al. in the original formulation.
+func (ts *timsorter[T]) mergeCollapse() {
+	for len(ts.runLen) > 1 {
+		n := len(ts.runLen) - 2
+		runLen := ts.runLen
+		if n > 0 && runLen[n-1] <= runLen[n]+runLen[n+1] ||
+			n > 1 && runLen[n-2] <= runLen[n-1]+runLen[n] {
+			if runLen[n-1] < runLen[n+1] {
+				n--
+			}
+		} else if runLen[n] > runLen[n+1] {
+			break
+		}
+		ts.mergeAt(n)
+	}
+}
+
+// mergeForceCollapse merges all pending runs into one
+func (ts *timsorter[T]) mergeForceCollapse() {
+	for len(ts.runLen) > 1 {
+		n := len(ts.runLen) - 2
+		if n > 0 && ts.runLen[n-1] < ts.runLen[n+1] {
+			n--
+		}
+		ts.mergeAt(n)
+	}
+}
+
+// mergeAt merges the pending runs i and i+1, which must be adjacent on the
+// stack. Elements already in place at the start of run i and the end of
+// run i+1 are skipped by galloping first.
+func (ts *timsorter[T]) mergeAt(i int) {
+	base1, len1 := ts.runBase[i], ts.runLen[i]
+	base2, len2 := ts.runBase[i+1], ts.runLen[i+1]
+
+	ts.runLen[i] = len1 + len2
+	ts.runBase = append(ts.runBase[:i+1], ts.runBase[i+2:]...)
+	ts.runLen = append(ts.runLen[:i+1], ts.runLen[i+2:]...)
+
+	d := ts.data
+	k := gallopRight(d[base2], d[base1:base1+len1], 0, ts.less)
+	base1 += k
+	len1 -= k
+	if len1 == 0 {
+		return
+	}
+
+	len2 = gallopLeft(d[base1+len1-1], d[base2:base2+len2], len2-1, ts.less)
+	if len2 == 0 {
+		return
+	}
+
+	if len1 <= len2 {
+		ts.mergeLo(base1, len1, base2, len2)
+	} else {
+		ts.mergeHi(base1, len1, base2, len2)
+	}
+}
+
+// gallopLeft returns the leftmost index at which key can be inserted into
+// the sorted slice a, searching outwards from hint by doubling steps and
+// then by binary search
+func gallopLeft[T any](key T, a []T, hint int, less func(a, b T) bool) int {
+	lastOfs, ofs := 0, 1
+	if less(a[hint], key) {
+		// a[hint] < key: gallop right until a[hint+lastOfs] < key <= a[hint+ofs]
+		maxOfs := len(a) - hint
+		for ofs < maxOfs && less(a[hint+ofs], key) {
+			lastOfs = ofs
+			ofs = ofs<<1 + 1
+		}
+		ofs = min(ofs, maxOfs)
+		lastOfs += hint
+		ofs += hint
+	} else {
+		// key <= a[hint]: gallop left until a[hint-ofs] < key <= a[hint-lastOfs]
+		maxOfs := hint + 1
+		for ofs < maxOfs && !less(a[hint-ofs], key) {
+			lastOfs = ofs
+			ofs = ofs<<1 + 1
+		}
+		ofs = min(ofs, maxOfs)
+		lastOfs, ofs = hint-ofs, hint-lastOfs
+	}
+
+	// a[lastOfs] < key <= a[ofs]
+	lastOfs++
+	for lastOfs < ofs {
+		m := lastOfs + (ofs-lastOfs)>>1
+		if less(a[m], key) {
+			lastOfs = m + 1
+		} else {
+			ofs = m
+		}
+	}
+	return ofs
+}
+
+// gallopRight is gallopLeft returning the rightmost insertion index, after
+// any elements equal to key
+func gallopRight[T any](key T, a []T, hint int, less func(a, b T) bool) int {
+	lastOfs, ofs := 0, 1
+	if less(key, a[hint]) {
+		// key < a[hint]: gallop left until a[hint-ofs] <= key < a[hint-lastOfs]
+		maxOfs := hint + 1
+		for ofs < maxOfs && less(key, a[hint-ofs]) {
+			lastOfs = ofs
+			ofs = ofs<<1 + 1
+		}
+		ofs = min(ofs, maxOfs)
+		lastOfs, ofs = hint-ofs, hint-lastOfs
+	} else {
+		// a[hint] <= key: gallop right until a[hint+lastOfs] <= key < a[hint+ofs]
+		maxOfs := len(a) - hint
+		for ofs < maxOfs && !less(key, a[hint+ofs]) {
+			lastOfs = ofs
+			ofs = ofs<<1 + 1
+		}
+		ofs = min(ofs, maxOfs)
+		lastOfs += hint
+		ofs += hint
+	}
+
+	// a[lastOfs] <= key < a[ofs]
+	lastOfs++
+	for lastOfs < ofs {
+		m := lastOfs + (ofs-lastOfs)>>1
+		if less(key, a[m]) {
+			ofs = m
+		} else {
+			lastOfs = m + 1
+		}
+	}
+	return ofs
+}
+
+// buffer returns a merge buffer of n elements, growing the retained one
+// geometrically but never past half the input
+func (ts *timsorter[T]) buffer(n int) []T {
+	if cap(ts.tmp) < n {
+		size := max(n, min(2*cap(ts.tmp), len(ts.data)/2))
+		ts.tmp = make([]T, size)
+	}
+	return ts.tmp[:n]
+}
+
+// mergeLo merges the adjacent runs data[base1:base1+len1] and
+// data[base2:base2+len2] from the left, buffering the first and shorter run.
+// The first element of run 2 must belong before run 1 and the last element
+// of run 1 after run 2.
+func (ts *timsorter[T]) mergeLo(base1, len1, base2, len2 int) {
+	d := ts.data
+	tmp := ts.buffer(len1)
+	copy(tmp, d[base1:base1+len1])
+
+	cursor1, cursor2, dest := 0, base2, base1
+	d[dest] = d[cursor2]
+	dest++
+	cursor2++
+	len2--
+	if len2 == 0 {
+		copy(d[dest:], tmp[cursor1:cursor1+len1])
+		return
+	}
+	if len1 == 1 {
+		copy(d[dest:], d[cursor2:cursor2+len2])
+		d[dest+len2] = tmp[cursor1]
+		return
+	}
+
+	minGallop := ts.minGallop
+outer:
+	for {
+		count1, count2 := 0, 0 // consecutive wins of each run
+
+		// Merge one element at a time until one run keeps winning
+		for {
+			if ts.less(d[cursor2], tmp[cursor1]) {
+				d[dest] = d[cursor2]
+				dest++
+				cursor2++
+				count2++
+				count1 = 0
+				len2--
+				if len2 == 0 {
+					break outer
+				}
+			} else {
+				d[dest] = tmp[cursor1]
+				dest++
+				cursor1++
+				count1++
+				count2 = 0
+				len1--
+				if len1 == 1 {
+					break outer
+				}
+			}
+			if count1|count2 >= minGallop {
+				break
+			}
+		}
+
+		// Gallop, copying whole blocks, until galloping stops paying off
+		for {
+			count1 = gallopRight(d[cursor2], tmp[cursor1:cursor1+len1], 0, ts.less)
+			if count1 != 0 {
+				copy(d[dest:], tmp[cursor1:cursor1+count1])
+				dest += count1
+				cursor1 += count1
+				len1 -= count1
+				if len1 <= 1 {
+					break outer
+				}
+			}
+			d[dest] = d[cursor2]
+			dest++
+			cursor2++
+			len2--
+			if len2 == 0 {
+				break outer
+			}
+
+			count2 = gallopLeft(tmp[cursor1], d[cursor2:cursor2+len2], 0, ts.less)
+			if count2 != 0 {
+				copy(d[dest:], d[cursor2:cursor2+count2])
+				dest += count2
+				cursor2 += count2
+				len2 -= count2
+				if len2 == 0 {
+					break outer
+				}
+			}
+			d[dest] = tmp[cursor1]
+			dest++
+			cursor1++
+			len1--
+			if len1 == 1 {
+				break outer
+			}
+
+			minGallop--
+			if count1 < timsortMinGallop && count2 < timsortMinGallop {
+				break
+			}
+		}
+		minGallop = max(minGallop, 0) + 2 // penalize leaving galloping mode
+	}
+	ts.minGallop = max(minGallop, 1)
+
+	// With a consistent less, run 1 always holds the last element. If it
+	// does not, the rest of run 2 is already in place.
+	if len1 == 1 {
+		copy(d[dest:], d[cursor2:cursor2+len2])
+		d[dest+len2] = tmp[cursor1]
+	} else if len1 > 0 {
+		copy(d[dest:], tmp[cursor1:cursor1+len1])
+	}
+}
+
+// mergeHi is mergeLo merging from the right, buffering the second and
+// shorter run
+func (ts *timsorter[T]) mergeHi(base1, len1, base2, len2 int) {
+	d := ts.data
+	tmp := ts.buffer(len2)
+	copy(tmp, d[base2:base2+len2])
+
+	cursor1, cursor2, dest := base1+len1-1, len2-1, base2+len2-1
+	d[dest] = d[cursor1]
+	dest--
+	cursor1--
+	len1--
+	if len1 == 0 {
+		copy(d[dest-(len2-1):], tmp[:len2])
+		return
+	}
+	if len2 == 1 {
+		dest -= len1
+		cursor1 -= len1
+		copy(d[dest+1:], d[cursor1+1:cursor1+1+len1])
+		d[dest] = tmp[cursor2]
+		return
+	}
+
+	minGallop := ts.minGallop
+outer:
+	for {
+		count1, count2 := 0, 0 // consecutive wins of each run
+
+		// Merge one element at a time until one run keeps winning
+		for {
+			if ts.less(tmp[cursor2], d[cursor1]) {
+				d[dest] = d[cursor1]
+				dest--
+				cursor1--
+				count1++
+				count2 = 0
+				len1--
+				if len1 == 0 {
+					break outer
+				}
+			} else {
+				d[dest] = tmp[cursor2]
+				dest--
+				cursor2--
+				count2++
+				count1 = 0
+				len2--
+				if len2 == 1 {
+					break outer
+				}
+			}
+			if count1|count2 >= minGallop {
+				break
+			}
+		}
+
+		// Gallop, copying whole blocks, until galloping stops paying off
+		for {
+			count1 = len1 - gallopRight(tmp[cursor2], d[base1:base1+len1], len1-1, ts.less)
+			if count1 != 0 {
+				dest -= count1
+				cursor1 -= count1
+				len1 -= count1
+				copy(d[dest+1:], d[cursor1+1:cursor1+1+count1])
+				if len1 == 0 {
+					break outer
+				}
+			}
+			d[dest] = tmp[cursor2]
+			dest--
+			cursor2--
+			len2--
+			if len2 == 1 {
+				break outer
+			}
+
+			count2 = len2 - gallopLeft(d[cursor1], tmp[:len2], len2-1, ts.less)
+			if count2 != 0 {
+				dest -= count2
+				cursor2 -= count2
+				len2 -= count2
+				copy(d[dest+1:], tmp[cursor2+1:cursor2+1+count2])
+				if len2 <= 1 {
+					break outer
+				}
+			}
+			d[dest] = d[cursor1]
+			dest--
+			cursor1--
+			len1--
+			if len1 == 0 {
+				break outer
+			}
+
+			minGallop--
+			if count1 < timsortMinGallop && count2 < timsortMinGallop {
+				break
+			}
+		}
+		minGallop = max(minGallop, 0) + 2 // penalize leaving galloping mode
+	}
+	ts.minGallop = max(minGallop, 1)
+
+	// With a consistent less, run 2 always holds the first element. If it
+	// does not, the rest of run 1 is already in place.
+	if len2 == 1 {
+		dest -= len1
+		cursor1 -= len1
+		copy(d[dest+1:], d[cursor1+1:cursor1+1+len1])
+		d[dest] = tmp[cursor2]
+	} else if len2 > 0 {
+		copy(d[dest-(len2-1):], tmp[:len2])
+	}
+}
@@ -0,0 +1,175 @@
+package pqueue
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// keyed is an element whose sort key has many duplicates and whose index
+// records the input order
+type keyed struct {
+	key, index int
+}
+
+// keyedInput returns n elements with keys drawn from the given number of
+// distinct values, arranged by shape
+func keyedInput(n, distinct int, shape string) []keyed {
+	keys := make([]int, n)
+	for i := range keys {
+		keys[i] = rand.Intn(distinct)
+	}
+	switch shape {
+	case "ascending":
+		slices.Sort(keys)
+	case "descending":
+		slices.Sort(keys)
+		slices.Reverse(keys)
+	case "runs":
+		// Sorted blocks of random length, so merges gallop
+		for lo := 0; lo < n; {
+			hi := min(n, lo+1+rand.Intn(2000))
+			slices.Sort(keys[lo:hi])
+			lo = hi
+		}
+	}
+
+	data := make([]keyed, n)
+	for i, k := range keys {
+		data[i] = keyed{key: k, index: i}
+	}
+	return data
+}
+
+// TestTimsortStable tests that timsort matches a stable sort exactly
+func TestTimsortStable(t *testing.T) {
+	less := func(a, b keyed) bool { return a.key < b.key }
+	for _, n := range []int{0, 1, 2, 31, 32, 33, 64, 65, 1000, 20000} {
+		for _, distinct := range []int{1, 3, 100, 1 << 30} {
+			for _, shape := range []string{"random", "ascending", "descending", "runs"} {
+				data := keyedInput(n, distinct, shape)
+				want := slices.Clone(data)
+				slices.SortStableFunc(want, func(a, b keyed) int { return a.key - b.key })
+
+				got := slices.Clone(data)
+				sliceQueue(got, less).SortWithStrategy(TimsortStrategy)
+				if !slices.Equal(got, want) {
+					t.Errorf("Size %d, %d keys, %s: result is not a stable sort", n, distinct, shape)
+				}
+			}
+		}
+	}
+}
+
+// TestTimsortDescendingRuns tests that descending runs with equal elements
+// keep those elements in order
+func TestTimsortDescendingRuns(t *testing.T) {
+	data := []keyed{{3, 0}, {2, 1}, {2, 2}, {1, 3}, {1, 4}, {0, 5}}
+	sliceQueue(data, func(a, b keyed) bool { return a.key < b.key }).SortWithStrategy(TimsortStrategy)
+	want := []keyed{{0, 5}, {1, 3}, {1, 4}, {2, 1}, {2, 2}, {3, 0}}
+	if !slices.Equal(data, want) {
+		t.Errorf("Got %v, want %v", data, want)
+	}
+}
+
+// TestTimsortAdaptive tests that presorted input costs linear comparisons
+// and interleaved runs are merged with galloping
+func TestTimsortAdaptive(t *testing.T) {
+	const n = 1 << 16
+	count := func(data []int) int {
+		comparisons := 0
+		pq := sliceQueue(data, func(a, b int) bool {
+			comparisons++
+			return a < b
+		})
+		pq.timsort()
+		if !slices.IsSorted(data) {
+			t.Fatal("Result not sorted")
+		}
+		return comparisons
+	}
+
+	sorted := make([]int, n)
+	for i := range sorted {
+		sorted[i] = i
+	}
+	if c := count(slices.Clone(sorted)); c != n-1 {
+		t.Errorf("Sorted input: %d comparisons, want %d", c, n-1)
+	}
+	reversed := slices.Clone(sorted)
+	slices.Reverse(reversed)
+	if c := count(reversed); c != n-1 {
+		t.Errorf("Reversed input: %d comparisons, want %d", c, n-1)
+	}
+
+	// Two sorted halves that barely overlap: finding the runs takes n-1
+	// comparisons and galloping skips the bulk of the merge
+	halves := make([]int, n)
+	for i := range halves[:n/2] {
+		halves[i] = i
+		halves[n/2+i] = n/2 - 10 + i
+	}
+	if c := count(halves); c > n+100 {
+		t.Errorf("Two sorted halves: %d comparisons", c)
+	}
+}
+
+// TestTimsortBuffer tests that merges never buffer more than half the input
+func TestTimsortBuffer(t *testing.T) {
+	data := make([]int, 10000)
+	for i := range data {
+		data[i] = rand.Int()
+	}
+	ts := &timsorter[int]{data: data, less: func(a, b int) bool { return a < b }, minGallop: timsortMinGallop}
+	for lo := 0; lo < len(data); lo += 100 {
+		slices.Sort(data[lo : lo+100])
+		ts.runBase = append(ts.runBase, lo)
+		ts.runLen = append(ts.runLen, 100)
+		ts.mergeCollapse()
+
+		// Run lengths must keep the collapse invariants
+		r := ts.runLen
+		for i := len(r) - 3; i >= 0; i-- {
+			if r[i] <= r[i+1]+r[i+2] {
+				t.Fatalf("Run stack %v violates the invariants", r)
+			}
+		}
+		if len(r) >= 2 && r[len(r)-2] <= r[len(r)-1] {
+			t.Fatalf("Run stack %v violates the invariants", r)
+		}
+	}
+	ts.mergeForceCollapse()
+
+	if !slices.IsSorted(data) {
+		t.Error("Result not sorted")
+	}
+	if cap(ts.tmp) > len(data)/2 {
+		t.Errorf("Buffer of %d elements for %d inputs", cap(ts.tmp), len(data))
+	}
+}
+
+// TestTimsortInconsistentLess tests that a less that is not a strict weak
+// order cannot lose or duplicate elements
+func TestTimsortInconsistentLess(t *testing.T) {
+	data := make([]int, 5000)
+	for i := range data {
+		data[i] = i
+	}
+	pq := sliceQueue(slices.Clone(data), func(a, b int) bool { return rand.Intn(2) == 0 })
+	pq.timsort()
+
+	got := pq.data
+	slices.Sort(got)
+	if !slices.Equal(got, data) {
+		t.Error("Elements were lost or duplicated")
+	}
+}
+
+// TestMinRunLength tests minrun against values from the reference
+func TestMinRunLength(t *testing.T) {
+	for n, want := range map[int]int{0: 0, 31: 31, 32: 16, 63: 32, 64: 16, 65: 17, 1000: 32, 2112: 17, 1 << 20: 16} {
+		if got := minRunLength(n); got != want {
+			t.Errorf("minRunLength(%d) = %d, want %d", n, got, want)
+		}
+	}
+}